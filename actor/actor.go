@@ -1,6 +1,11 @@
 package actor
 
 import (
+	"errors"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/thlcodes/go-actress/log"
@@ -19,105 +24,472 @@ type Actor interface {
 	Handle(ctx Context, msg Message) (reply Message, err error)
 }
 
+// HandlerFunc handles a message like Actor.Handle
+type HandlerFunc func(ctx Context, msg Message) (reply Message, err error)
+
 type actor struct {
-	log          log.Logger
-	impl         Actor
-	stopper      chan struct{}
-	mailbox      chan *Envelope
-	dropWhenFull bool
+	log            log.Logger
+	system         *system
+	ref            localRef
+	ctx            *actorContext
+	name           string
+	impl           Actor
+	typ            reflect.Type
+	factory        func() Actor
+	stopper        chan struct{}
+	killOnce       sync.Once
+	started        chan error
+	stopped        atomic.Bool
+	terminated     atomic.Bool
+	done           chan struct{}
+	mailboxFactory MailboxFactory
+	passivation    time.Duration
+
+	// stashed envelopes, only touched by the loop
+	stashed   []*Envelope
+	stashSize int
+
+	// behavior stack replacing Handle, only touched by the loop
+	behaviors []HandlerFunc
+
+	scheduler      *actorScheduler
+	middleware     []Middleware
+	handler        HandlerFunc
+	stats          *stats
+	router         *router
+	receiveTimeout receiveTimer
+
+	// guards the mailbox while the actor is passivated or reactivated
+	mu      sync.RWMutex
+	active  bool
+	mailbox *lanes
+
+	// guards draining the mailbox once the loop of a stopped actor exited
+	drainLock sync.Mutex
+	drained   bool
+
+	restartPolicy RestartPolicy
+
+	// supervision, guarded by the system lock
+	parent   *actor
+	children []*actor
+	strategy SupervisorStrategy
+	restarts []time.Time
+
+	// death watch, guarded by the system lock
+	watchers map[*actor]struct{}
+	watching map[*actor]struct{}
 }
 
 /* Actor impl */
 
-func newActor(impl Actor, mailboxSize uint, log log.Logger) *actor {
-	return &actor{
-		log:     log,
-		impl:    impl,
-		mailbox: make(chan *Envelope, mailboxSize),
-		stopper: make(chan struct{}, 1), // make buffered so that stopping never blocks
+func newActor(impl Actor, mailboxFactory MailboxFactory, log log.Logger) *actor {
+	a := &actor{
+		log:            log,
+		impl:           impl,
+		typ:            reflect.TypeOf(impl),
+		mailboxFactory: mailboxFactory,
+		stopper:        make(chan struct{}, 1), // make buffered so that stopping never blocks
+		done:           make(chan struct{}),
+		passivation:    DefaultPassivationTimeout,
+		stashSize:      DefaultStashSize,
+		strategy:       DefaultSupervisorStrategy,
 	}
+	a.scheduler = newActorScheduler(a)
+	return a
 }
 
-// start the actor with the given context
-func (a *actor) start(ctx Context) {
+// start the actor unless a message already activated it
+func (a *actor) start() {
 	a.log.Trace("start()")
-	go a.loop(ctx)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.active && !a.stopped.Load() {
+		a.activate()
+	}
+}
+
+// activate pushes the Start message and runs the loop, the mailbox is
+// fresh unless messages arrived while passivating, a.mu must be held
+func (a *actor) activate() {
+	a.log.Debug("> activating")
+	if a.impl == nil {
+		a.impl = a.factory()
+	}
+	if a.mailbox == nil {
+		a.mailbox = newLanes(a.mailboxFactory(), a.mailboxFactory())
+		for _, m := range []Mailbox{a.mailbox.priority, a.mailbox.user} {
+			if m, ok := m.(DroppingMailbox); ok {
+				m.OnDrop(a.dropped)
+			}
+		}
+	}
+	_ = a.mailbox.push(NewEnvelope(&Start{}))
+	a.active = true
+	a.system.loops.Add(1)
+	go a.loop(a.ctx)
+}
+
+// passivate stops the actor if its mailbox is empty and nobody is sending
+// to it right now, the loop deactivates it once it exited
+func (a *actor) passivate(ctx *actorContext) bool {
+	if !a.mu.TryLock() {
+		return false
+	}
+	idle := a.mailbox.len() == 0 && len(a.stashed) == 0 && !a.stopped.Load()
+	a.mu.Unlock()
+	if !idle {
+		return false
+	}
+	a.log.Debug("> passivating")
+	// not locked, the hooks may send to the actor itself
+	stop := NewEnvelope(&Stop{})
+	stopCtx, done := ctx.receive(stop)
+	_ = a.handle(stopCtx, stop)
+	a.postStop(stopCtx)
+	done()
+	return true
+}
+
+// deactivate drops the mailbox of a passivated actor and the instance if it
+// can be recreated, messages that arrived while passivating or a stop start
+// the next activation right away
+func (a *actor) deactivate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.factory != nil {
+		a.impl = nil
+		a.behaviors = nil
+	}
+	if a.mailbox.len() > 0 || len(a.stashed) > 0 || a.stopped.Load() {
+		a.activate()
+		return
+	}
+	a.active = false
+	a.mailbox = nil
+}
+
+// deliver puts the envelope into the mailbox, reactivating the actor if it is passivated
+func (a *actor) deliver(envelope *Envelope) error {
+	for {
+		a.mu.RLock()
+		if a.stopped.Load() {
+			a.mu.RUnlock()
+			return ErrActorNotFound(&a.ref)
+		}
+		if !a.active {
+			a.mu.RUnlock()
+			a.mu.Lock()
+			if !a.active && !a.stopped.Load() {
+				a.activate()
+			}
+			a.mu.Unlock()
+			continue
+		}
+		sampleEnqueued(envelope)
+		err := a.mailbox.push(envelope)
+		a.mu.RUnlock()
+		if err == nil && a.stopped.Load() {
+			// a blocked push might have completed after the loop's final drain
+			a.drain(false)
+		}
+		if errors.Is(err, ErrMailboxOverflow) {
+			a.recordDropped()
+			if envelope.published {
+				// reporting it would feed the event stream with its own failures
+				a.log.Debug("mailbox full, dropping published %T", envelope.msg)
+				return ErrMailboxFull(&a.ref)
+			}
+			a.log.Warn("mailbox full")
+			a.system.events.Publish(&MailboxFull{Ref: &a.ref})
+			return ErrMailboxFull(&a.ref)
+		}
+		return err
+	}
+}
+
+// mailboxLen returns the amount of queued envelopes, 0 while passivated
+func (a *actor) mailboxLen() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if !a.active {
+		return 0
+	}
+	return a.mailbox.len()
+}
+
+// dropped is called for envelopes evicted from the mailbox
+func (a *actor) dropped(envelope *Envelope) {
+	a.recordDropped()
+	if envelope.published {
+		// like rejected ones, evicted events are not published again
+		return
+	}
+	a.system.events.Publish(&MailboxFull{Ref: &a.ref})
+	a.system.deadLetter(&a.ref, envelope, ErrMailboxFull(&a.ref))
 }
 
 // stop the actor
 func (a *actor) stop(graceful bool) {
 	a.log.Trace("stop(graceful=%t)", graceful)
+	a.mu.RLock()
+	if !a.stopped.CompareAndSwap(false, true) {
+		a.mu.RUnlock()
+		if !graceful {
+			// already stopping, do not wait for the mailbox to drain
+			a.kill()
+		}
+		return
+	}
+	if !a.active {
+		a.mu.RUnlock()
+		// passivated, there is no loop to stop
+		var reason error
+		if !graceful {
+			reason = ErrActorKilled
+		}
+		a.terminate(reason)
+		return
+	}
 	if graceful {
-		a.channel() <- NewEnvelope(&Stop{})
+		// the system lane never rejects
+		_ = a.mailbox.push(NewEnvelope(&Stop{}))
 	} else {
-		close(a.stopper)
+		a.kill()
 	}
+	a.mu.RUnlock()
 }
 
-// channel returns the mailbox channel
-func (a *actor) channel() chan<- *Envelope {
-	return a.mailbox
+// kill the loop without handling the remaining messages
+func (a *actor) kill() {
+	a.killOnce.Do(func() { close(a.stopper) })
 }
 
-func (a *actor) loop(ctx Context) {
+// loop runs the lifecycle of an activation: PreStart, the messages until
+// the actor stops or is passivated, PostStop
+func (a *actor) loop(ctx *actorContext) {
 	a.log.Trace("loop()")
-	closeMailbox := true
-loop:
+	defer a.system.loops.Done()
+	if reason := a.preStart(ctx.idle()); reason != nil {
+		a.log.Debug("> pre start failed: %s", reason)
+		a.stopped.Store(true)
+		a.drain(true)
+		a.terminate(reason)
+		a.signalStarted(reason)
+		return
+	}
+	a.signalStarted(nil)
+	reason, passivated := a.run(ctx)
+	if passivated {
+		// not stopped, the next message reactivates the actor
+		a.deactivate()
+		return
+	}
+	a.stopped.Store(true)
+	// whatever is left will never be handled
+	a.unstashAll()
+	a.drain(true)
+	a.postStop(ctx.idle())
+	a.terminate(reason)
+}
+
+// drain dead-letters the envelopes left in the mailbox of a stopped actor,
+// once the loop exited it is up to the senders to drain what they pushed
+func (a *actor) drain(exited bool) {
+	a.drainLock.Lock()
+	defer a.drainLock.Unlock()
+	a.drained = a.drained || exited
+	if !a.drained {
+		// the loop is still popping
+		return
+	}
+	for envelope, ok := a.mailbox.pop(); ok; envelope, ok = a.mailbox.pop() {
+		if _, ok := envelope.msg.(systemMessage); !ok {
+			a.system.deadLetter(&a.ref, envelope, ErrActorStopped(&a.ref))
+		}
+	}
+}
+
+// signalStarted tells the spawner how the first activation went
+func (a *actor) signalStarted(err error) {
+	if a.started != nil {
+		a.started <- err
+		a.started = nil
+	}
+}
+
+// run handles the messages until the actor stops, returns why, nil if
+// graceful, or whether it was passivated
+func (a *actor) run(ctx *actorContext) (reason error, passivated bool) {
+	var idle <-chan time.Time
+	var idleTimer *time.Timer
+	if a.passivation > 0 {
+		idleTimer = time.NewTimer(a.passivation)
+		defer idleTimer.Stop()
+		idle = idleTimer.C
+	}
+	defer a.receiveTimeout.stop()
+	// graceful stop, handled once the mailbox is drained
+	var stopping *Envelope
 	for {
-		select {
-		case envelope, ok := <-a.mailbox:
-			// received a message envelope from the mailbox
-			if !ok {
-				a.log.Debug("> mailbox is closed")
-				// stop when mailbox is closed
-				closeMailbox = false
-				break loop
+		if a.mailbox.len() == 0 {
+			if stopping != nil {
+				a.log.Debug("> mailbox drained, stopping")
+				stopCtx, done := ctx.receive(stopping)
+				_ = a.handle(stopCtx, stopping)
+				done()
+				return nil, false
 			}
-			a.log.Debug("> received envelope {%s}", envelope)
-			// handel message with copy of current context extended with sender
-			a.handle(ctx.WithSender(envelope.sender), envelope)
-			// stop actor when message was the stop signal
-			if _, ok := envelope.msg.(*Stop); ok {
-				a.log.Debug("> got a stop message")
-				break loop
+			// wait for the next envelope
+			select {
+			case <-a.mailbox.system.Ready():
+			case <-a.mailbox.priority.Ready():
+			case <-a.mailbox.user.Ready():
+			case <-idle:
+				if a.passivate(ctx) {
+					return nil, true
+				}
+				idleTimer.Reset(a.passivation)
+				continue
+			case <-a.receiveTimeout.C():
+				a.log.Debug("> receive timeout")
+				_ = a.mailbox.push(NewEnvelope(&ReceiveTimeout{}))
+				continue
+			case <-ctx.Done():
+			case <-a.stopper:
 			}
+		}
+		select {
 		case <-ctx.Done():
 			a.log.Debug("> context is done")
 			// supervised stop through context
 			// this is handled as graceful stop but
 			// all messages left in mailbox will not be
 			// processed
-			a.handle(ctx, NewEnvelope(&Stop{}))
-			break loop
+			stop := NewEnvelope(&Stop{})
+			stopCtx, done := ctx.receive(stop)
+			_ = a.handle(stopCtx, stop)
+			done()
+			return ctx.Err(), false
 		case <-a.stopper:
 			a.log.Debug("> received stop message")
 			// ungraceful stop
-			break loop
+			return ErrActorKilled, false
+		default:
+		}
+		envelope, ok := a.mailbox.pop()
+		if !ok {
+			continue
+		}
+		// received a message envelope from the mailbox
+		a.log.Debug("> received envelope {%s}", envelope)
+		if idleTimer != nil {
+			idleTimer.Reset(a.passivation)
+		}
+		switch msg := envelope.msg.(type) {
+		case *restart:
+			// restart requested by the supervisor due to a failed sibling
+			a.restart(ctx, msg.reason)
+			continue
+		case *Stop:
+			// stop after the messages queued so far, new ones are rejected
+			a.log.Debug("> got a stop message")
+			a.stopped.Store(true)
+			stopping = envelope
+			continue
+		}
+		if envelope.timer != nil && envelope.timer.Cancelled() {
+			a.log.Debug("> dropping message of cancelled timer")
+			continue
+		}
+		// handel message with current context extended with sender and deadline
+		msgCtx, done := ctx.receive(envelope)
+		failure := a.handle(msgCtx, envelope)
+		done()
+		_, system := envelope.msg.(systemMessage)
+		a.receiveTimeout.handled(!system)
+		if failure != nil {
+			restarting, backoff := a.system.supervise(a, failure)
+			if !restarting {
+				a.log.Debug("> stopped by supervisor")
+				return failure, false
+			}
+			if backoff > 0 {
+				a.log.Debug("> restarting in %s", backoff)
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+					return ctx.Err(), false
+				case <-a.stopper:
+					return ErrActorKilled, false
+				}
+			}
+			a.restart(ctx, failure)
 		}
 	}
-	if closeMailbox {
-		a.log.Debug("> closing mailbox due to closeMailbox=true")
-		close(a.mailbox)
+}
+
+// invoke the actor implementation, turning panics into failures
+func (a *actor) invoke(ctx Context, msg Message) (reply Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = Fatal(&PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+	return a.handler(ctx, msg)
+}
+
+// dispatch the message to the current behavior, the innermost handler
+func (a *actor) dispatch(ctx Context, msg Message) (Message, error) {
+	if n := len(a.behaviors); n > 0 {
+		return a.behaviors[n-1](ctx, msg)
+	}
+	return a.impl.Handle(ctx, msg)
+}
+
+// restart the actor, with a fresh instance if there is a factory
+func (a *actor) restart(ctx *actorContext, reason error) {
+	a.log.Debug("> restarting due to %s", reason)
+	// not the failed envelope but the Start about to be handled
+	start := NewEnvelope(&Start{})
+	startCtx, done := ctx.receive(start)
+	defer done()
+	a.preRestart(startCtx, reason)
+	a.behaviors = nil
+	a.receiveTimeout.set(0)
+	if a.factory != nil {
+		a.impl = a.factory()
 	}
+	a.postRestart(startCtx, reason)
+	_ = a.handle(startCtx, start)
 }
 
 // handle message, send reply/error to sender if
-// there is one in the contex
-func (a *actor) handle(ctx Context, envelope *Envelope) {
+// there is one in the contex, returns the reason
+// if the actor failed
+func (a *actor) handle(ctx Context, envelope *Envelope) (failure error) {
 	msg := envelope.Msg()
 	a.log.Trace("handle(ctx,msg=%T)", msg)
-	var err error
-	var reply Message
-	reply, err = a.impl.Handle(ctx, msg)
-	if ctx.Sender() == nil || envelope.isTell {
+	var start time.Time
+	if a.recordReceived(envelope) {
+		start = time.Now()
+	}
+	reply, err := a.invoke(ctx, msg)
+	a.recordHandled(start, err)
+	var f *Failure
+	if errors.As(err, &f) {
+		failure = f.Reason
+	}
+	if ctx.Sender() == nil || envelope.isTell || a.isStashed(envelope) {
 		return
 	}
+	a.recordReply()
 	if err != nil {
 		a.log.Debug("> sending error %s to sender %s", err, ctx.Sender())
-		_ = ctx.Tell(ctx.Sender(), &Error{Error: err})
+		_ = ctx.Tell(ctx.Sender(), &Error{Error: err}, WithCorrelationID(envelope.id))
 	} else {
 		a.log.Debug("> sending reply %T to sender %s", reply, ctx.Sender())
-		_ = ctx.Tell(ctx.Sender(), reply)
+		_ = ctx.Tell(ctx.Sender(), reply, WithCorrelationID(envelope.id))
 	}
-
+	return
 }
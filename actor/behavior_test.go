@@ -0,0 +1,106 @@
+package actor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+type openMsg struct {
+	actor.Message
+}
+
+type closeMsg struct {
+	actor.Message
+}
+
+// doorActor stashes knocks while closed and answers them while open
+type doorActor struct{}
+
+func (da *doorActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg.(type) {
+	case ackMsg:
+		return nil, ctx.Stash()
+	case openMsg:
+		ctx.BecomeStacked(da.open)
+		ctx.UnstashAll()
+	}
+	return nil, nil
+}
+
+func (da *doorActor) open(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case ackMsg:
+		return ackMsg{i: msg.i + 100}, nil
+	case closeMsg:
+		ctx.Unbecome()
+	}
+	return nil, nil
+}
+
+func TestBecome(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&doorActor{})
+	require.NoError(t, err)
+
+	knock := sys.AskAsync(ref, ackMsg{i: 1})
+	require.NoError(t, sys.Tell(ref, openMsg{}))
+	reply, err := sys.Ask(ref, ackMsg{i: 2})
+	require.NoError(t, err)
+	require.Equal(t, ackMsg{i: 102}, reply)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reply, err = knock.Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ackMsg{i: 101}, reply)
+
+	// back to Handle, knocks are stashed again
+	require.NoError(t, sys.Tell(ref, closeMsg{}))
+	knock = sys.AskAsync(ref, ackMsg{i: 3})
+	select {
+	case <-knock.Done():
+		t.Fatal("knock answered while closed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.NoError(t, sys.Tell(ref, openMsg{}))
+	reply, err = knock.Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ackMsg{i: 103}, reply)
+}
+
+// switchActor replaces its behavior on every message
+type switchActor struct{}
+
+func (sa *switchActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	if _, ok := msg.(*actor.Start); ok {
+		return nil, nil
+	}
+	ctx.Become(sa.second)
+	return ackMsg{i: 1}, nil
+}
+
+func (sa *switchActor) second(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	ctx.Become(sa.third)
+	return ackMsg{i: 2}, nil
+}
+
+func (sa *switchActor) third(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	ctx.Unbecome()
+	return ackMsg{i: 3}, nil
+}
+
+func TestBecomeReplaces(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&switchActor{})
+	require.NoError(t, err)
+	for _, i := range []int{1, 2, 3, 1} {
+		reply, err := sys.Ask(ref, ackMsg{})
+		require.NoError(t, err)
+		require.Equal(t, ackMsg{i: i}, reply)
+	}
+}
@@ -1,6 +1,9 @@
 package actor
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Context interface {
 	context.Context
@@ -11,64 +14,227 @@ type Context interface {
 
 	talker
 
+	namedTalker
+
 	System() System
 	Inner() context.Context
 
 	WithSender(sender Ref) Context
+
+	// Envelope of the message currently handled
+	Envelope() *Envelope
+	// Header of the message currently handled
+	Header(name string) (any, bool)
+
+	// Watch ref to get Terminated once it stopped
+	Watch(ref Ref) error
+	Unwatch(ref Ref) error
+
+	// Stash sets the current envelope aside until UnstashAll, Start and Stop cannot be stashed
+	Stash() error
+	// UnstashAll puts the stashed envelopes back in front of the mailbox
+	UnstashAll()
+
+	// Become handles the next messages with handler instead of the current behavior
+	Become(handler HandlerFunc)
+	// BecomeStacked handles the next messages with handler until Unbecome
+	BecomeStacked(handler HandlerFunc)
+	// Unbecome returns to the previous behavior, eventually the actor's Handle
+	Unbecome()
+
+	// Scheduler whose schedules are cancelled when the actor stops
+	Scheduler() Scheduler
+	// Timers sending to Self
+	Timers() Timers
+
+	// SetReceiveTimeout to get ReceiveTimeout once no user message
+	// was received for timeout, 0 cancels it
+	SetReceiveTimeout(timeout time.Duration)
 }
 
+// actorContext is shared by the contexts of all messages of an actor
 type actorContext struct {
+	// the actor's context without any message deadline
 	context.Context
 
-	system System
+	system *system
+	actor  *actor
 
-	self   Ref
-	sender Ref
+	self Ref
 }
 
-var _ Context = (*actorContext)(nil)
+// messageContext is the context a message is handled with, it never
+// changes, so it may be used from other goroutines after handling
+type messageContext struct {
+	context.Context
+	*actorContext
 
-func newActorContext(ctx context.Context, system System, self Ref) Context {
+	sender   Ref
+	envelope *Envelope
+}
+
+var _ Context = (*messageContext)(nil)
+
+func newActorContext(ctx context.Context, actor *actor) *actorContext {
 	return &actorContext{
 		Context: ctx,
-		system:  system,
-		self:    self,
+		system:  actor.system,
+		actor:   actor,
+		self:    &actor.ref,
+	}
+}
+
+// receive creates the context for handling the envelope, carrying the envelope,
+// its sender and deadline, the deadline is released by calling done
+func (c *actorContext) receive(envelope *Envelope) (ctx *messageContext, done func()) {
+	ctx = &messageContext{
+		Context:      c.Context,
+		actorContext: c,
+		sender:       envelope.sender,
+		envelope:     envelope,
 	}
+	deadline, ok := envelope.Deadline()
+	if !ok {
+		return ctx, func() {}
+	}
+	ctx.Context, done = context.WithDeadline(c.Context, deadline)
+	return ctx, done
 }
 
-func (c *actorContext) WithSender(sender Ref) Context {
-	c.sender = sender
-	return c
+// idle returns the context for the lifecycle hooks run without a message
+func (c *actorContext) idle() *messageContext {
+	return &messageContext{Context: c.Context, actorContext: c}
 }
 
-func (c *actorContext) System() System {
+// WithSender returns a copy of the context with the given sender
+func (c *messageContext) WithSender(sender Ref) Context {
+	ctx := *c
+	ctx.sender = sender
+	return &ctx
+}
+
+func (c *messageContext) System() System {
 	return c.system
 }
 
-func (c *actorContext) Self() Ref {
+func (c *messageContext) Self() Ref {
 	return c.self
 }
 
-func (c *actorContext) Sender() Ref {
+func (c *messageContext) Sender() Ref {
 	return c.sender
 }
 
-func (c *actorContext) Inner() context.Context {
+func (c *messageContext) Envelope() *Envelope {
+	return c.envelope
+}
+
+func (c *messageContext) Header(name string) (any, bool) {
+	if c.envelope == nil {
+		return nil, false
+	}
+	return c.envelope.Header(name)
+}
+
+func (c *messageContext) Inner() context.Context {
 	return c.Context
 }
 
-func (c *actorContext) Tell(whom Ref, what Message, opts ...TalkOption) error {
-	//return c.system.Tell(whom, what, append([]TalkOption{WithSender(c.self)}, opts...)...)
-	return c.system.Tell(whom, what, opts...)
+func (c *messageContext) Tell(whom Ref, what Message, opts ...TalkOption) error {
+	return c.system.Tell(whom, what, append([]TalkOption{internal, propagateHeaders(c.envelope)}, opts...)...)
+}
+
+func (c *messageContext) Ask(whom Ref, what Message, opts ...TalkOption) (reply Message, err error) {
+	return c.system.Ask(whom, what, append([]TalkOption{WithSender(c.self), internal, propagateHeaders(c.envelope)}, opts...)...)
+}
+
+func (c *messageContext) TellName(name string, what Message, opts ...TalkOption) error {
+	return c.system.TellName(name, what, append([]TalkOption{internal, propagateHeaders(c.envelope)}, opts...)...)
+}
+
+func (c *messageContext) AskContext(ctx context.Context, whom Ref, what Message, opts ...TalkOption) (reply Message, err error) {
+	return c.system.AskContext(ctx, whom, what, append([]TalkOption{WithSender(c.self), internal, propagateHeaders(c.envelope)}, opts...)...)
+}
+
+// AskAsync does not block the actor, pipe the future to Self to
+// handle the reply as message
+func (c *messageContext) AskAsync(whom Ref, what Message, opts ...TalkOption) *Future {
+	return c.system.AskAsync(whom, what, append([]TalkOption{WithSender(c.self), internal, propagateHeaders(c.envelope)}, opts...)...)
+}
+
+func (c *messageContext) AskName(name string, what Message, opts ...TalkOption) (reply Message, err error) {
+	return c.system.AskName(name, what, append([]TalkOption{WithSender(c.self), internal, propagateHeaders(c.envelope)}, opts...)...)
 }
 
-func (c *actorContext) Ask(whom Ref, what Message, opts ...TalkOption) (reply Message, err error) {
-	return c.system.Ask(whom, what, append([]TalkOption{WithSender(c.self)}, opts...)...)
+// Spawn a child actor that is supervised by and dies with this actor
+func (c *messageContext) Spawn(actor Actor, opts ...SpawnOption) (Ref, error) {
+	return c.system.spawn(actor, c.self, opts...)
 }
 
-func (c *actorContext) Spawn(actor Actor, opts ...SpawnOption) Ref {
-	return c.system.Spawn(actor, opts...)
+// SpawnPool spawns a router with its routees as child of this actor
+func (c *messageContext) SpawnPool(factory func() Actor, size int, strategy RoutingStrategy, opts ...SpawnOption) (Ref, error) {
+	return c.system.spawnPool(c.self, factory, size, strategy, opts...)
 }
-func (c *actorContext) Kill(ref Ref, graceful bool) error {
+
+// SpawnGroup spawns a router over existing actors as child of this actor
+func (c *messageContext) SpawnGroup(refs []Ref, strategy RoutingStrategy, opts ...SpawnOption) (Ref, error) {
+	return c.system.spawnGroup(c.self, refs, strategy, opts...)
+}
+
+func (c *messageContext) Kill(ref Ref, graceful bool) error {
 	return c.system.Kill(ref, graceful)
 }
+
+func (c *messageContext) Watch(ref Ref) error {
+	return c.system.watch(c.self, ref)
+}
+
+func (c *messageContext) Unwatch(ref Ref) error {
+	return c.system.unwatch(c.self, ref)
+}
+
+func (c *messageContext) Stash() error {
+	if c.envelope == nil {
+		return ErrNothingToStash
+	}
+	switch c.envelope.msg.(type) {
+	case *Start, *Stop:
+		// lifecycle messages are not stashed
+		return ErrNothingToStash
+	}
+	return c.actor.stash(c.envelope)
+}
+
+func (c *messageContext) UnstashAll() {
+	c.actor.unstashAll()
+}
+
+func (c *messageContext) Become(handler HandlerFunc) {
+	if n := len(c.actor.behaviors); n > 0 {
+		c.actor.behaviors[n-1] = handler
+		return
+	}
+	c.actor.behaviors = append(c.actor.behaviors, handler)
+}
+
+func (c *messageContext) BecomeStacked(handler HandlerFunc) {
+	c.actor.behaviors = append(c.actor.behaviors, handler)
+}
+
+func (c *messageContext) Unbecome() {
+	if n := len(c.actor.behaviors); n > 0 {
+		c.actor.behaviors = c.actor.behaviors[:n-1]
+	}
+}
+
+func (c *messageContext) Scheduler() Scheduler {
+	return c.actor.scheduler
+}
+
+func (c *messageContext) Timers() Timers {
+	return c.actor.scheduler
+}
+
+func (c *messageContext) SetReceiveTimeout(timeout time.Duration) {
+	c.actor.receiveTimeout.set(timeout)
+}
@@ -0,0 +1,134 @@
+package actor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// at most one dead letter log line per interval
+	DeadLetterLogInterval = 1 * time.Second
+
+	// the amount of dead letters waiting to be published
+	DeadLetterQueueSize = 1000
+)
+
+// DeadLetter is published for every envelope that could not be delivered
+type DeadLetter struct {
+	Message
+	Recipient Ref
+	Sender    Ref
+	Msg       Message
+	Reason    error
+}
+
+// DeadLetterOffice publishes dead letters to its subscribers
+type DeadLetterOffice interface {
+	// Subscribe ref to all dead letters, stopped subscribers are removed
+	Subscribe(ref Ref)
+	Unsubscribe(ref Ref)
+	// Count returns the amount of dead letters so far
+	Count() uint64
+}
+
+var _ DeadLetterOffice = (*deadLetterOffice)(nil)
+
+type deadLetterOffice struct {
+	system *system
+	queue  chan *DeadLetter
+	count  atomic.Uint64
+
+	lock        sync.RWMutex
+	subscribers map[string]Ref
+
+	log logLimiter
+}
+
+func newDeadLetterOffice(ctx context.Context, system *system) *deadLetterOffice {
+	o := &deadLetterOffice{
+		system:      system,
+		queue:       make(chan *DeadLetter, DeadLetterQueueSize),
+		subscribers: map[string]Ref{},
+		log:         logLimiter{interval: DeadLetterLogInterval},
+	}
+	go o.run(ctx)
+	return o
+}
+
+func (o *deadLetterOffice) Subscribe(ref Ref) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.subscribers[ref.String()] = ref
+}
+
+func (o *deadLetterOffice) Unsubscribe(ref Ref) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	delete(o.subscribers, ref.String())
+}
+
+func (o *deadLetterOffice) Count() uint64 {
+	return o.count.Load()
+}
+
+// post a dead letter without ever blocking
+func (o *deadLetterOffice) post(dl *DeadLetter) {
+	o.count.Add(1)
+	o.logRateLimited(dl)
+	o.system.events.Publish(dl)
+	select {
+	case o.queue <- dl:
+	default:
+		// too many dead letters, only counted and logged
+	}
+}
+
+func (o *deadLetterOffice) logRateLimited(dl *DeadLetter) {
+	if suppressed, ok := o.log.allow(); ok {
+		o.system.log.Warn("dead letter %T from %v to %s: %s (%d more suppressed)", dl.Msg, dl.Sender, dl.Recipient, dl.Reason, suppressed)
+	}
+}
+
+// run publishes the queued dead letters to the subscribers until ctx is done
+func (o *deadLetterOffice) run(ctx context.Context) {
+	for {
+		select {
+		case dl := <-o.queue:
+			o.publish(dl)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (o *deadLetterOffice) publish(dl *DeadLetter) {
+	o.lock.RLock()
+	subscribers := make([]Ref, 0, len(o.subscribers))
+	for _, ref := range o.subscribers {
+		subscribers = append(subscribers, ref)
+	}
+	o.lock.RUnlock()
+	for _, ref := range o.system.fanOut(dl, subscribers) {
+		o.Unsubscribe(ref)
+	}
+}
+
+// deadLetter handles an envelope that could not be delivered to whom
+func (s *system) deadLetter(whom Ref, envelope *Envelope, reason error) {
+	if _, ok := envelope.msg.(*DeadLetter); ok {
+		return
+	}
+	s.deadLetters.post(&DeadLetter{
+		Recipient: whom,
+		Sender:    envelope.sender,
+		Msg:       envelope.msg,
+		Reason:    reason,
+	})
+}
+
+// DeadLetters returns the office publishing undeliverable envelopes
+func (s *system) DeadLetters() DeadLetterOffice {
+	return s.deadLetters
+}
@@ -0,0 +1,102 @@
+package actor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+func expectDeadLetter(t *testing.T, msgs chan actor.Message) *actor.DeadLetter {
+	t.Helper()
+	select {
+	case msg := <-msgs:
+		require.IsType(t, &actor.DeadLetter{}, msg)
+		return msg.(*actor.DeadLetter)
+	case <-time.After(time.Second):
+		t.Fatal("no dead letter")
+	}
+	return nil
+}
+
+func TestDeadLetters(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 10)}
+	fwdRef, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+	sys.DeadLetters().Subscribe(fwdRef)
+
+	// unknown actor
+	ref, err := sys.Spawn(&ackActor{})
+	require.NoError(t, err)
+	require.NoError(t, sys.Kill(ref, true))
+	require.Error(t, sys.Tell(ref, ackMsg{i: 1}))
+	dl := expectDeadLetter(t, fwd.msgs)
+	require.Equal(t, ref.String(), dl.Recipient.String())
+	require.Equal(t, ackMsg{i: 1}, dl.Msg)
+	require.Error(t, dl.Reason)
+
+	// full mailbox
+	a := &orderActor{order: make(chan int, 10)}
+	ref, err = sys.Spawn(a, actor.WithMailbox(1, true))
+	require.NoError(t, err)
+	// the gate must be popped, otherwise it is what is left when killed
+	gate, entered := make(chan struct{}), make(chan struct{})
+	require.NoError(t, sys.Tell(ref, gateMsg{gate: gate, entered: entered}))
+	<-entered
+	require.Eventually(t, func() bool {
+		return sys.Tell(ref, ackMsg{i: 2}) != nil
+	}, time.Second, time.Millisecond)
+	dl = expectDeadLetter(t, fwd.msgs)
+	require.ErrorIs(t, dl.Reason, actor.ErrMailboxOverflow)
+
+	// left in the mailbox when killed
+	require.NoError(t, sys.Kill(ref, false))
+	close(gate)
+	dl = expectDeadLetter(t, fwd.msgs)
+	require.Equal(t, ackMsg{i: 2}, dl.Msg)
+
+	require.GreaterOrEqual(t, sys.DeadLetters().Count(), uint64(3))
+
+	// no more dead letters after unsubscribing
+	sys.DeadLetters().Unsubscribe(fwdRef)
+	require.Error(t, sys.Tell(ref, ackMsg{i: 3}))
+	select {
+	case msg := <-fwd.msgs:
+		t.Fatalf("unexpected %T", msg)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestDeadLettersSlowSubscriber(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fast := &forwardActor{msgs: make(chan actor.Message, 10)}
+	fastRef, err := sys.Spawn(fast)
+	require.NoError(t, err)
+	sys.DeadLetters().Subscribe(fastRef)
+
+	// the slow subscriber is busy and its blocking mailbox is full
+	slow := &orderActor{order: make(chan int, 10)}
+	slowRef, err := sys.Spawn(slow, actor.WithMailbox(1, false))
+	require.NoError(t, err)
+	sys.DeadLetters().Subscribe(slowRef)
+	gate, entered := make(chan struct{}), make(chan struct{})
+	defer close(gate)
+	require.NoError(t, sys.Tell(slowRef, gateMsg{gate: gate, entered: entered}))
+	<-entered
+	require.NoError(t, sys.Tell(slowRef, ackMsg{}))
+
+	// the fast one still gets every dead letter
+	ref, err := sys.Spawn(&ackActor{})
+	require.NoError(t, err)
+	require.NoError(t, sys.Kill(ref, false))
+	for i := 1; i <= 5; i++ {
+		require.Error(t, sys.Tell(ref, ackMsg{i: i}))
+	}
+	for i := 1; i <= 5; i++ {
+		require.Equal(t, ackMsg{i: i}, expectDeadLetter(t, fast.msgs).Msg)
+	}
+}
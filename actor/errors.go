@@ -3,27 +3,49 @@ package actor
 import (
 	"errors"
 	"fmt"
+	"reflect"
 )
 
 // system
 var (
-	ErrUnsupportedRef = func(ref Ref) error { return fmt.Errorf("system cannot handle ref %s for now", ref) }
+	ErrUnsupportedRef   = func(ref Ref) error { return fmt.Errorf("system cannot handle ref %s for now", ref) }
+	ErrNameTaken        = func(name string) error { return fmt.Errorf("an actor with name %q is already registered", name) }
+	ErrPreStartFailed   = func(ref Ref, err error) error { return fmt.Errorf("pre start of actor %s failed: %w", ref, err) }
+	ErrShuttingDown     = errors.New("system is shutting down")
+	ErrInvalidEventType = func(sampleOrType any) error { return fmt.Errorf("cannot subscribe to %v", sampleOrType) }
 )
 
 // talk errors
 var (
 	ErrUnsupportedRefForTalking = func(ref Ref) error { return fmt.Errorf("talking to ref %s is currently not supported", ref) }
 	ErrActorNotFound            = func(ref Ref) error { return fmt.Errorf("could not find local actor %s", ref) }
-	ErrChannelRefChannelClosed  = func(ref Ref) error { return fmt.Errorf("somehow the channel of the channel ref %s was closed", ref) }
-	ErrTalkTimeout              = errors.New("talk timeout")
+	ErrNameNotFound             = func(name string) error { return fmt.Errorf("could not find actor with name %q", name) }
+	// Deprecated: channel refs were replaced by futures, it is not returned anymore
+	ErrChannelRefChannelClosed = func(ref Ref) error { return fmt.Errorf("somehow the channel of the channel ref %s was closed", ref) }
+	ErrActorStopped            = func(ref Ref) error { return fmt.Errorf("actor %s stopped before handling the message", ref) }
+	ErrTalkTimeout             = errors.New("talk timeout")
+	ErrLateReply               = errors.New("reply arrived after the asker stopped waiting")
+	ErrUncorrelatedReply       = errors.New("reply does not correlate with the request")
+	ErrUnexpectedMessage       = func(msg Message, expected reflect.Type) error {
+		return fmt.Errorf("got %T, expected %s", msg, expected)
+	}
+	ErrUnexpectedReply = func(reply Message, expected reflect.Type) error {
+		return fmt.Errorf("got reply %T, expected %s", reply, expected)
+	}
+	ErrNoRoutees  = func(ref Ref) error { return fmt.Errorf("router %s has no routees", ref) }
+	ErrUnroutable = func(ref Ref) error { return fmt.Errorf("router %s found no routee for the message", ref) }
 )
 
 // actor errors
 var (
 	ErrActorNotImplemented = errors.New("actor not implemented yet")
+	ErrActorKilled         = errors.New("actor was killed")
+	ErrNothingToStash      = errors.New("no envelope to stash")
+	ErrStashFull           = func(ref Ref) error { return fmt.Errorf("stash of actor %s is full", ref) }
 )
 
 // mailbox errors
 var (
-	ErrMailboxFull = func(ref Ref) error { return fmt.Errorf("mailbox of actor %s is full", ref) }
+	ErrMailboxOverflow = errors.New("no room left in mailbox")
+	ErrMailboxFull     = func(ref Ref) error { return fmt.Errorf("mailbox of actor %s is full: %w", ref, ErrMailboxOverflow) }
 )
@@ -0,0 +1,210 @@
+package actor
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// the amount of events waiting to be published
+	EventStreamQueueSize = 1000
+
+	// at most one log line about dropped events per interval
+	EventStreamLogInterval = 1 * time.Second
+)
+
+// ActorSpawned is published once an actor started
+type ActorSpawned struct {
+	Message
+	Ref    Ref
+	Parent Ref
+}
+
+// ActorStopped is published once an actor terminated, Reason is nil if it stopped gracefully
+type ActorStopped struct {
+	Message
+	Ref    Ref
+	Reason error
+}
+
+// MailboxFull is published whenever an actor's mailbox rejects or drops a message
+type MailboxFull struct {
+	Message
+	Ref Ref
+}
+
+// EventStream publishes messages to the actors subscribed to their type
+type EventStream interface {
+	// Subscribe ref to the messages of the given type, either a reflect.Type or a sample
+	// value, use a nil pointer like (*Iface)(nil) for interface types, stopped
+	// subscribers are removed
+	Subscribe(ref Ref, sampleOrType any) error
+	// Unsubscribe ref from the given type, or from all if nil
+	Unsubscribe(ref Ref, sampleOrType any)
+	Publish(msg Message)
+}
+
+var _ EventStream = (*eventStream)(nil)
+
+type eventStream struct {
+	system  *system
+	queue   chan Message
+	dropLog logLimiter
+
+	lock        sync.RWMutex
+	subscribers map[string]*subscriber
+}
+
+type subscriber struct {
+	ref   Ref
+	types []reflect.Type
+}
+
+func newEventStream(ctx context.Context, system *system) *eventStream {
+	es := &eventStream{
+		system:      system,
+		queue:       make(chan Message, EventStreamQueueSize),
+		dropLog:     logLimiter{interval: EventStreamLogInterval},
+		subscribers: map[string]*subscriber{},
+	}
+	go es.run(ctx)
+	return es
+}
+
+// eventType resolves the type to subscribe to
+func eventType(sampleOrType any) (reflect.Type, error) {
+	switch t := sampleOrType.(type) {
+	case nil:
+		return nil, ErrInvalidEventType(sampleOrType)
+	case reflect.Type:
+		return t, nil
+	}
+	t := reflect.TypeOf(sampleOrType)
+	if t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Interface {
+		return t.Elem(), nil
+	}
+	return t, nil
+}
+
+func (es *eventStream) Subscribe(ref Ref, sampleOrType any) error {
+	typ, err := eventType(sampleOrType)
+	if err != nil {
+		return err
+	}
+	es.lock.Lock()
+	defer es.lock.Unlock()
+	sub, ok := es.subscribers[ref.String()]
+	if !ok {
+		sub = &subscriber{ref: ref}
+		es.subscribers[ref.String()] = sub
+	}
+	if !slices.Contains(sub.types, typ) {
+		sub.types = append(sub.types, typ)
+	}
+	return nil
+}
+
+func (es *eventStream) Unsubscribe(ref Ref, sampleOrType any) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+	sub, ok := es.subscribers[ref.String()]
+	if !ok {
+		return
+	}
+	typ, err := eventType(sampleOrType)
+	if err == nil {
+		sub.types = slices.DeleteFunc(sub.types, func(t reflect.Type) bool { return t == typ })
+	}
+	if err != nil || len(sub.types) == 0 {
+		delete(es.subscribers, ref.String())
+	}
+}
+
+// Publish the message without ever blocking, it is dropped if too many are waiting
+func (es *eventStream) Publish(msg Message) {
+	select {
+	case es.queue <- msg:
+	default:
+		if suppressed, ok := es.dropLog.allow(); ok {
+			es.system.log.Warn("event stream is full, dropping %T (%d more suppressed)", msg, suppressed)
+		}
+	}
+}
+
+// run publishes the queued messages to the subscribers until ctx is done
+func (es *eventStream) run(ctx context.Context) {
+	for {
+		select {
+		case msg := <-es.queue:
+			es.publish(msg)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (es *eventStream) publish(msg Message) {
+	typ := reflect.TypeOf(msg)
+	if typ == nil {
+		return
+	}
+	es.lock.RLock()
+	var recipients []Ref
+	for _, sub := range es.subscribers {
+		if slices.ContainsFunc(sub.types, func(t reflect.Type) bool {
+			return t == typ || (t.Kind() == reflect.Interface && typ.Implements(t))
+		}) {
+			recipients = append(recipients, sub.ref)
+		}
+	}
+	es.lock.RUnlock()
+	for _, ref := range es.system.fanOut(msg, recipients) {
+		es.Unsubscribe(ref, nil)
+	}
+}
+
+// fanOut delivers msg to the subscribers without ever blocking, those with a
+// full mailbox miss it, returns the subscribers that stopped
+func (s *system) fanOut(msg Message, subscribers []Ref) (stopped []Ref) {
+	for _, ref := range subscribers {
+		a, ok := s.local(ref)
+		if !ok {
+			stopped = append(stopped, ref)
+			continue
+		}
+		if err := a.deliver(NewEnvelope(msg, Tell, published, nonBlocking)); err != nil && a.stopped.Load() {
+			stopped = append(stopped, ref)
+		}
+	}
+	return
+}
+
+// logLimiter lets at most one log line per interval pass
+type logLimiter struct {
+	lock       sync.Mutex
+	interval   time.Duration
+	last       time.Time
+	suppressed int
+}
+
+// allow returns whether to log now and how many lines were suppressed before
+func (l *logLimiter) allow() (suppressed int, ok bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if time.Since(l.last) < l.interval {
+		l.suppressed++
+		return 0, false
+	}
+	suppressed = l.suppressed
+	l.last = time.Now()
+	l.suppressed = 0
+	return suppressed, true
+}
+
+// EventStream returns the system wide event stream
+func (s *system) EventStream() EventStream {
+	return s.events
+}
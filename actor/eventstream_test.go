@@ -0,0 +1,135 @@
+package actor_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+// expectPublished skips other messages until one of type T arrives
+func expectPublished[T actor.Message](t *testing.T, msgs chan actor.Message, match func(T) bool) T {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case msg := <-msgs:
+			if published, ok := msg.(T); ok && match(published) {
+				return published
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("no %T published", zero)
+			return zero
+		}
+	}
+}
+
+func TestEventStreamPublish(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 10)}
+	ref, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+
+	// interface types match every implementation
+	require.NoError(t, sys.EventStream().Subscribe(ref, (*actor.Hashable)(nil)))
+	require.NoError(t, sys.EventStream().Subscribe(ref, reflect.TypeFor[ackMsg]()))
+	require.Error(t, sys.EventStream().Subscribe(ref, nil))
+	sys.EventStream().Publish(hashMsg{userID: "a"})
+	sys.EventStream().Publish(gateMsg{})
+	sys.EventStream().Publish(ackMsg{i: 1})
+	expectMsg(t, fwd.msgs, hashMsg{userID: "a"})
+	expectMsg(t, fwd.msgs, ackMsg{i: 1})
+
+	sys.EventStream().Unsubscribe(ref, ackMsg{})
+	sys.EventStream().Publish(ackMsg{i: 2})
+	sys.EventStream().Publish(hashMsg{userID: "b"})
+	expectMsg(t, fwd.msgs, hashMsg{userID: "b"})
+
+	sys.EventStream().Unsubscribe(ref, nil)
+	sys.EventStream().Publish(hashMsg{userID: "c"})
+	expectNoMsg(t, fwd.msgs, 10*time.Millisecond)
+}
+
+func TestEventStreamSystemEvents(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 100)}
+	fwdRef, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+	for _, sample := range []any{&actor.ActorSpawned{}, &actor.ActorStopped{}, &actor.DeadLetter{}, &actor.MailboxFull{}} {
+		require.NoError(t, sys.EventStream().Subscribe(fwdRef, sample))
+	}
+
+	a := &orderActor{order: make(chan int, 10)}
+	ref, err := sys.Spawn(a, actor.WithMailbox(1, true))
+	require.NoError(t, err)
+	expectPublished(t, fwd.msgs, func(e *actor.ActorSpawned) bool { return e.Ref.String() == ref.String() })
+
+	gate := make(chan struct{})
+	require.NoError(t, sys.Tell(ref, gateMsg{gate: gate}))
+	require.Eventually(t, func() bool {
+		return sys.Tell(ref, ackMsg{}) != nil
+	}, time.Second, time.Millisecond)
+	expectPublished(t, fwd.msgs, func(e *actor.MailboxFull) bool { return e.Ref.String() == ref.String() })
+	expectPublished(t, fwd.msgs, func(*actor.DeadLetter) bool { return true })
+
+	require.NoError(t, sys.Kill(ref, false))
+	close(gate)
+	stopped := expectPublished(t, fwd.msgs, func(e *actor.ActorStopped) bool { return e.Ref.String() == ref.String() })
+	require.ErrorIs(t, stopped.Reason, actor.ErrActorKilled)
+}
+
+func TestEventStreamFullSubscriber(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 100)}
+	fwdRef, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+	require.NoError(t, sys.EventStream().Subscribe(fwdRef, &actor.MailboxFull{}))
+
+	// a full subscriber to MailboxFull does not get its own failures published
+	a := &orderActor{order: make(chan int, 10)}
+	ref, err := sys.Spawn(a, actor.WithMailbox(1, true))
+	require.NoError(t, err)
+	require.NoError(t, sys.EventStream().Subscribe(ref, &actor.MailboxFull{}))
+	gate, entered := make(chan struct{}), make(chan struct{})
+	defer close(gate)
+	require.NoError(t, sys.Tell(ref, gateMsg{gate: gate, entered: entered}))
+	<-entered
+	require.NoError(t, sys.Tell(ref, ackMsg{}))
+	require.Error(t, sys.Tell(ref, ackMsg{}))
+	expectPublished(t, fwd.msgs, func(e *actor.MailboxFull) bool { return e.Ref.String() == ref.String() })
+	expectNoMsg(t, fwd.msgs, 50*time.Millisecond)
+}
+
+func TestEventStreamSlowSubscriber(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fast := &forwardActor{msgs: make(chan actor.Message, 10)}
+	fastRef, err := sys.Spawn(fast)
+	require.NoError(t, err)
+	require.NoError(t, sys.EventStream().Subscribe(fastRef, ackMsg{}))
+
+	// the slow subscriber is busy and its blocking mailbox is full
+	slow := &orderActor{order: make(chan int, 10)}
+	slowRef, err := sys.Spawn(slow, actor.WithMailbox(1, false))
+	require.NoError(t, err)
+	require.NoError(t, sys.EventStream().Subscribe(slowRef, ackMsg{}))
+	gate, entered := make(chan struct{}), make(chan struct{})
+	defer close(gate)
+	require.NoError(t, sys.Tell(slowRef, gateMsg{gate: gate, entered: entered}))
+	<-entered
+	require.NoError(t, sys.Tell(slowRef, ackMsg{}))
+
+	// the fast one still gets every event
+	for i := 1; i <= 5; i++ {
+		sys.EventStream().Publish(ackMsg{i: i})
+	}
+	for i := 1; i <= 5; i++ {
+		expectMsg(t, fast.msgs, ackMsg{i: i})
+	}
+}
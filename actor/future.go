@@ -0,0 +1,217 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Future is the pending reply of an AskAsync.
+// Callbacks run on their own goroutine once the future is completed, in the
+// order they were added, panicking callbacks are logged.
+type Future struct {
+	system *system
+
+	lock      sync.Mutex
+	done      chan struct{}
+	reply     Message
+	err       error
+	callbacks []func(Message, error)
+}
+
+// Replies is the reply of a future returned by All
+type Replies struct {
+	Message
+	Replies []Message
+}
+
+func newFuture(system *system) *Future {
+	return &Future{
+		system: system,
+		done:   make(chan struct{}),
+	}
+}
+
+// complete the future, only the first completion counts
+func (f *Future) complete(reply Message, err error) bool {
+	f.lock.Lock()
+	select {
+	case <-f.done:
+		f.lock.Unlock()
+		return false
+	default:
+	}
+	f.reply, f.err = reply, err
+	close(f.done)
+	callbacks := f.callbacks
+	f.callbacks = nil
+	f.lock.Unlock()
+	f.dispatch(callbacks...)
+	return true
+}
+
+// dispatch the callbacks of the completed future, never on the goroutine
+// completing it, which is most likely the replying actor's
+func (f *Future) dispatch(callbacks ...func(Message, error)) {
+	if len(callbacks) == 0 {
+		return
+	}
+	go func() {
+		for _, cb := range callbacks {
+			f.call(cb)
+		}
+	}()
+}
+
+// call cb with the result, turning panics into log lines
+func (f *Future) call(cb func(Message, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			f.system.log.Error("future callback panicked: %v\n%s", r, debug.Stack())
+		}
+	}()
+	cb(f.reply, f.err)
+}
+
+// Done is closed when the future is completed
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await blocks until the future is completed or the context is done
+func (f *Future) Await(ctx context.Context) (reply Message, err error) {
+	select {
+	case <-f.done:
+		return f.reply, f.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrTalkTimeout, ctx.Err())
+	}
+}
+
+// OnComplete calls cb with the result once the future is completed,
+// right away if it already is
+func (f *Future) OnComplete(cb func(reply Message, err error)) {
+	f.lock.Lock()
+	select {
+	case <-f.done:
+		f.lock.Unlock()
+		f.dispatch(cb)
+	default:
+		f.callbacks = append(f.callbacks, cb)
+		f.lock.Unlock()
+	}
+}
+
+// Then returns a future completed with the result of fn applied to the reply,
+// errors and *Error replies are passed on as error without calling fn,
+// the future fails with a *PanicError if fn panics
+func (f *Future) Then(fn func(reply Message) (Message, error)) *Future {
+	next := newFuture(f.system)
+	f.OnComplete(func(reply Message, err error) {
+		reply, err = result(reply, err)
+		if err != nil {
+			next.complete(nil, err)
+			return
+		}
+		next.complete(apply(fn, reply))
+	})
+	return next
+}
+
+// apply fn to the reply, turning panics into errors
+func apply(fn func(reply Message) (Message, error), reply Message) (next Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(reply)
+}
+
+// PipeTo tells the reply to ref once the future is completed, errors are
+// sent as *Error, the reply is dead-lettered if the mailbox of ref is full
+func (f *Future) PipeTo(ref Ref, opts ...TalkOption) {
+	f.OnComplete(func(reply Message, err error) {
+		if err != nil {
+			reply = &Error{Error: err}
+		}
+		if err := f.system.Tell(ref, reply, append(opts, nonBlocking)...); err != nil {
+			f.system.log.Warn("could not pipe reply to %s: %s", ref, err)
+		}
+	})
+}
+
+// All returns a future completed with the Replies of all futures in order,
+// or with the first error, *Error replies count as error
+func All(future *Future, more ...*Future) *Future {
+	all := newFuture(future.system)
+	futures := append([]*Future{future}, more...)
+	var lock sync.Mutex
+	replies := make([]Message, len(futures))
+	pending := len(futures)
+	for i, f := range futures {
+		f.OnComplete(func(reply Message, err error) {
+			reply, err = result(reply, err)
+			if err != nil {
+				all.complete(nil, err)
+				return
+			}
+			lock.Lock()
+			replies[i] = reply
+			pending--
+			last := pending == 0
+			lock.Unlock()
+			if last {
+				all.complete(&Replies{Replies: replies}, nil)
+			}
+		})
+	}
+	return all
+}
+
+// Any returns a future completed with the first successful reply,
+// or with all errors if every future failed, *Error replies count as error
+func Any(future *Future, more ...*Future) *Future {
+	first := newFuture(future.system)
+	futures := append([]*Future{future}, more...)
+	var lock sync.Mutex
+	errs := make([]error, 0, len(futures))
+	for _, f := range futures {
+		f.OnComplete(func(reply Message, err error) {
+			reply, err = result(reply, err)
+			if err == nil {
+				first.complete(reply, nil)
+				return
+			}
+			lock.Lock()
+			errs = append(errs, err)
+			failed := len(errs) == len(futures)
+			lock.Unlock()
+			if failed {
+				first.complete(nil, errors.Join(errs...))
+			}
+		})
+	}
+	return first
+}
+
+// FirstOf returns a future completed with the result of the first completed future
+func FirstOf(future *Future, more ...*Future) *Future {
+	first := newFuture(future.system)
+	for _, f := range append([]*Future{future}, more...) {
+		f.OnComplete(func(reply Message, err error) {
+			first.complete(reply, err)
+		})
+	}
+	return first
+}
+
+// result turns an *Error reply into an error
+func result(reply Message, err error) (Message, error) {
+	if e, ok := reply.(*Error); ok && err == nil {
+		return nil, e.Error
+	}
+	return reply, err
+}
@@ -0,0 +1,196 @@
+package actor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+// forwardActor forwards all messages but Start to a channel
+type forwardActor struct {
+	msgs chan actor.Message
+}
+
+func (fa *forwardActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg.(type) {
+	case *actor.Start, *actor.Stop:
+	default:
+		fa.msgs <- msg
+	}
+	return nil, nil
+}
+
+func TestFutureAwaitAndThen(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&ackActor{})
+	require.NoError(t, err)
+
+	future := sys.AskAsync(ref, ackMsg{i: 1})
+	reply, err := future.Await(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, reply.(ackMsg).i)
+
+	reply, err = sys.AskAsync(ref, ackMsg{i: 2}).Then(func(reply actor.Message) (actor.Message, error) {
+		return ackMsg{i: reply.(ackMsg).i * 10}, nil
+	}).Await(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 20, reply.(ackMsg).i)
+
+	completed := make(chan actor.Message, 1)
+	sys.AskAsync(ref, ackMsg{i: 3}).OnComplete(func(reply actor.Message, err error) {
+		completed <- reply
+	})
+	select {
+	case reply := <-completed:
+		require.Equal(t, 3, reply.(ackMsg).i)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestFutureTimeout(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&sleepyActor{})
+	require.NoError(t, err)
+
+	_, err = sys.AskAsync(ref, sleepMsg{d: 500 * time.Millisecond}, actor.WithTimeout(10*time.Millisecond)).Await(context.Background())
+	require.ErrorIs(t, err, actor.ErrTalkTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = sys.AskAsync(ref, sleepMsg{d: 500 * time.Millisecond}).Await(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFuturePipeTo(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&ackActor{})
+	require.NoError(t, err)
+	fwd := &forwardActor{msgs: make(chan actor.Message, 1)}
+	fwdRef, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+
+	sys.AskAsync(ref, ackMsg{i: 4}).PipeTo(fwdRef)
+	select {
+	case msg := <-fwd.msgs:
+		require.Equal(t, 4, msg.(ackMsg).i)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestFuturePanickingCallbacks(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&ackActor{})
+	require.NoError(t, err)
+
+	future := sys.AskAsync(ref, ackMsg{i: 1})
+	future.OnComplete(func(actor.Message, error) { panic("boom") })
+	completed := make(chan actor.Message, 1)
+	future.OnComplete(func(reply actor.Message, err error) { completed <- reply })
+	select {
+	case reply := <-completed:
+		require.Equal(t, 1, reply.(ackMsg).i)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	// a panicking Then fails the next future
+	_, err = future.Then(func(actor.Message) (actor.Message, error) { panic("boom") }).Await(context.Background())
+	var p *actor.PanicError
+	require.ErrorAs(t, err, &p)
+	require.Equal(t, "boom", p.Value)
+
+	// the replying actor is still alive
+	reply, err := sys.Ask(ref, ackMsg{i: 2})
+	require.NoError(t, err)
+	require.Equal(t, 2, reply.(ackMsg).i)
+}
+
+func TestFuturePipeToFullMailbox(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 10)}
+	fwdRef, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+	sys.DeadLetters().Subscribe(fwdRef)
+	ref, err := sys.Spawn(&ackActor{})
+	require.NoError(t, err)
+
+	// the target is busy and its blocking mailbox is full
+	target, err := sys.Spawn(&orderActor{order: make(chan int, 10)}, actor.WithMailbox(1, false))
+	require.NoError(t, err)
+	gate, entered := make(chan struct{}), make(chan struct{})
+	defer close(gate)
+	require.NoError(t, sys.Tell(target, gateMsg{gate: gate, entered: entered}))
+	<-entered
+	require.NoError(t, sys.Tell(target, ackMsg{i: 1}))
+
+	// the piped reply is dead-lettered instead of waiting
+	sys.AskAsync(ref, ackMsg{i: 2}).PipeTo(target)
+	dl := expectDeadLetter(t, fwd.msgs)
+	require.Equal(t, ackMsg{i: 2}, dl.Msg)
+	require.ErrorIs(t, dl.Reason, actor.ErrMailboxOverflow)
+}
+
+func TestFutureCombinators(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	// every ask gets its own actor to not wait for each other
+	ask := func(d time.Duration) *actor.Future {
+		ref, err := sys.Spawn(&sleepyActor{})
+		require.NoError(t, err)
+		return sys.AskAsync(ref, sleepMsg{d: d}, actor.WithTimeout(100*time.Millisecond))
+	}
+
+	reply, err := actor.All(ask(0), ask(10*time.Millisecond), ask(0)).Await(context.Background())
+	require.NoError(t, err)
+	require.Len(t, reply.(*actor.Replies).Replies, 3)
+
+	_, err = actor.All(ask(0), ask(200*time.Millisecond)).Await(context.Background())
+	require.ErrorIs(t, err, actor.ErrTalkTimeout)
+
+	reply, err = actor.Any(ask(200*time.Millisecond), ask(0)).Await(context.Background())
+	require.NoError(t, err)
+	require.IsType(t, deadlineReply{}, reply)
+
+	_, err = actor.Any(ask(200*time.Millisecond), ask(200*time.Millisecond)).Await(context.Background())
+	require.ErrorIs(t, err, actor.ErrTalkTimeout)
+
+	reply, err = actor.FirstOf(ask(200*time.Millisecond), ask(0)).Await(context.Background())
+	require.NoError(t, err)
+	require.IsType(t, deadlineReply{}, reply)
+}
+
+func TestFutureErrorReplies(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	adder := spawnAdder(t, sys)
+	fail := func() *actor.Future { return sys.AskAsync(adder.Ref(), addMsg{amount: -1}) }
+	succeed := func() *actor.Future { return sys.AskAsync(adder.Ref(), addMsg{amount: 1}) }
+
+	_, err := actor.All(succeed(), fail()).Await(context.Background())
+	require.EqualError(t, err, "negative")
+
+	reply, err := actor.Any(fail(), succeed()).Await(context.Background())
+	require.NoError(t, err)
+	require.IsType(t, sumMsg{}, reply)
+	_, err = actor.Any(fail(), fail()).Await(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "negative")
+
+	called := false
+	_, err = fail().Then(func(reply actor.Message) (actor.Message, error) {
+		called = true
+		return reply, nil
+	}).Await(context.Background())
+	require.EqualError(t, err, "negative")
+	require.False(t, called)
+}
@@ -0,0 +1,100 @@
+package actor
+
+import (
+	"cmp"
+	"hash/fnv"
+	"slices"
+	"strconv"
+)
+
+// the amount of virtual nodes per routee by default
+const DefaultVirtualNodes = 100
+
+// Hashable messages are routed by their key by consistent hashing routers
+type Hashable interface {
+	HashKey() string
+}
+
+// HashKeyFunc extracts the key to route the message by, ok is false if there is none
+type HashKeyFunc func(msg Message) (key string, ok bool)
+
+// ConsistentHashing routes messages with the same key to the same routee, adding
+// or removing a routee only moves the keys of its share of the virtualNodes. The key
+// is taken from extract if set, or from Hashable messages, messages without a key
+// go to the dead letters
+func ConsistentHashing(virtualNodes int, extract HashKeyFunc) RoutingStrategy {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	return &consistentHashing{virtualNodes: virtualNodes, extract: extract}
+}
+
+type consistentHashing struct {
+	virtualNodes int
+	extract      HashKeyFunc
+
+	// the ring, rebuilt whenever the routees change
+	ring []ringNode
+}
+
+type ringNode struct {
+	hash   uint64
+	routee *actor
+}
+
+// hashKey spreads similar keys over the whole ring, fnv alone clusters short keys
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+func (ch *consistentHashing) instance() RoutingStrategy {
+	return &consistentHashing{virtualNodes: ch.virtualNodes, extract: ch.extract}
+}
+
+func (ch *consistentHashing) key(msg Message) (string, bool) {
+	if ch.extract != nil {
+		if key, ok := ch.extract(msg); ok {
+			return key, true
+		}
+	}
+	if h, ok := msg.(Hashable); ok {
+		return h.HashKey(), true
+	}
+	return "", false
+}
+
+func (ch *consistentHashing) route(msg Message, _ []*actor) []*actor {
+	key, ok := ch.key(msg)
+	if !ok || len(ch.ring) == 0 {
+		return nil
+	}
+	hash := hashKey(key)
+	i, _ := slices.BinarySearchFunc(ch.ring, hash, func(n ringNode, hash uint64) int {
+		return cmp.Compare(n.hash, hash)
+	})
+	if i == len(ch.ring) {
+		i = 0
+	}
+	return []*actor{ch.ring[i].routee}
+}
+
+func (ch *consistentHashing) routeesChanged(routees []*actor) {
+	ring := make([]ringNode, 0, len(routees)*ch.virtualNodes)
+	for _, r := range routees {
+		for v := 0; v < ch.virtualNodes; v++ {
+			ring = append(ring, ringNode{hash: hashKey(r.ref.String() + "#" + strconv.Itoa(v)), routee: r})
+		}
+	}
+	slices.SortFunc(ring, func(a, b ringNode) int {
+		return cmp.Compare(a.hash, b.hash)
+	})
+	ch.ring = ring
+}
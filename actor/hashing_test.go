@@ -0,0 +1,128 @@
+package actor_test
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+type hashMsg struct {
+	actor.Message
+	userID string
+}
+
+func (hm hashMsg) HashKey() string {
+	return hm.userID
+}
+
+func spawnWorkers(t *testing.T, sys actor.System, n int) (refs []actor.Ref) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		ref, err := sys.Spawn(&routeeActor{})
+		require.NoError(t, err)
+		refs = append(refs, ref)
+	}
+	return
+}
+
+func routeKeys(t *testing.T, sys actor.System, group actor.Ref, keys int) map[string]string {
+	t.Helper()
+	routed := map[string]string{}
+	for i := 0; i < keys; i++ {
+		id := strconv.Itoa(i)
+		reply, err := sys.Ask(group, hashMsg{userID: id})
+		require.NoError(t, err)
+		routed[id] = reply.(childRef).Ref.String()
+	}
+	return routed
+}
+
+func TestConsistentHashing(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	workers := spawnWorkers(t, sys, 4)
+	group, err := sys.SpawnGroup(workers, actor.ConsistentHashing(0, nil))
+	require.NoError(t, err)
+
+	// the same key always goes to the same routee, all routees get a share
+	keys := 400
+	routed := routeKeys(t, sys, group, keys)
+	require.Equal(t, routed, routeKeys(t, sys, group, keys))
+	shares := map[string]int{}
+	for _, ref := range routed {
+		shares[ref]++
+	}
+	require.Len(t, shares, 4)
+
+	// adding a routee only moves keys to it
+	extra := spawnWorkers(t, sys, 1)[0]
+	require.NoError(t, sys.Tell(group, &actor.AddRoutee{Ref: extra}))
+	moved := 0
+	for id, ref := range routeKeys(t, sys, group, keys) {
+		if ref != routed[id] {
+			require.Equal(t, extra.String(), ref)
+			moved++
+		}
+	}
+	require.Greater(t, moved, 0)
+	require.Less(t, moved, keys/2)
+
+	// removing it moves them back
+	require.NoError(t, sys.Kill(extra, true))
+	require.Eventually(t, func() bool {
+		return len(routees(t, sys, group)) == 4
+	}, time.Second, time.Millisecond)
+	require.Equal(t, routed, routeKeys(t, sys, group, keys))
+
+	// messages without a key are not routed
+	require.Error(t, sys.Tell(group, ackMsg{}))
+
+	// unless the key is extracted
+	group, err = sys.SpawnGroup(workers, actor.ConsistentHashing(10, func(msg actor.Message) (string, bool) {
+		ack, ok := msg.(ackMsg)
+		return strconv.Itoa(ack.i), ok
+	}))
+	require.NoError(t, err)
+	first := askRoutee(t, sys, group)
+	for i := 0; i < 5; i++ {
+		require.Equal(t, first, askRoutee(t, sys, group))
+	}
+}
+
+func TestConsistentHashingShared(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	workers := spawnWorkers(t, sys, 4)
+	strategy := actor.ConsistentHashing(10, nil)
+	groups := make([]actor.Ref, 2)
+	for i := range groups {
+		group, err := sys.SpawnGroup(workers[2*i:2*i+2], strategy)
+		require.NoError(t, err)
+		groups[i] = group
+	}
+
+	// every group routes to its own routees, even while the other one changes
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			extra, err := sys.Spawn(&routeeActor{})
+			if err != nil {
+				return
+			}
+			_ = sys.Tell(groups[1], &actor.AddRoutee{Ref: extra})
+			_ = sys.Tell(groups[1], &actor.RemoveRoutee{Ref: extra})
+		}
+	}()
+	own := []string{workers[0].String(), workers[1].String()}
+	for id, ref := range routeKeys(t, sys, groups[0], 100) {
+		require.True(t, slices.Contains(own, ref), "key %s routed to %s", id, ref)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,92 @@
+package actor
+
+import (
+	"maps"
+	"slices"
+)
+
+// HeaderKey names a header with values of type T
+type HeaderKey[T any] struct {
+	name      string
+	propagate bool
+}
+
+// NewHeaderKey returns the key of the header with the given name
+func NewHeaderKey[T any](name string) HeaderKey[T] {
+	return HeaderKey[T]{name: name}
+}
+
+// Propagated returns the key of a header that is passed on to the messages
+// sent with Context.Tell and Context.Ask while handling a message carrying it
+func (k HeaderKey[T]) Propagated() HeaderKey[T] {
+	k.propagate = true
+	return k
+}
+
+// Name of the header
+func (k HeaderKey[T]) Name() string {
+	return k.name
+}
+
+// Get the header of the envelope
+func (k HeaderKey[T]) Get(e *Envelope) (value T, ok bool) {
+	if e == nil {
+		return value, false
+	}
+	h, ok := e.headers[k.name]
+	if !ok {
+		return value, false
+	}
+	value, ok = h.value.(T)
+	return value, ok
+}
+
+// From gets the header of the message currently handled
+func (k HeaderKey[T]) From(ctx Context) (T, bool) {
+	return k.Get(ctx.Envelope())
+}
+
+type header struct {
+	value     any
+	propagate bool
+}
+
+// WithHeader sets a header of the envelope
+func WithHeader[T any](key HeaderKey[T], value T) TalkOption {
+	return func(e *Envelope) {
+		e.setHeader(key.name, header{value: value, propagate: key.propagate})
+	}
+}
+
+func (e *Envelope) setHeader(name string, h header) {
+	if e.headers == nil {
+		e.headers = map[string]header{}
+	}
+	e.headers[name] = h
+}
+
+// Header returns the value of the header with the given name
+func (e *Envelope) Header(name string) (any, bool) {
+	h, ok := e.headers[name]
+	return h.value, ok
+}
+
+// HeaderNames returns the names of all headers, sorted
+func (e *Envelope) HeaderNames() []string {
+	return slices.Sorted(maps.Keys(e.headers))
+}
+
+// propagateHeaders passes on the propagated headers of from, headers set
+// by later options win
+func propagateHeaders(from *Envelope) TalkOption {
+	return func(e *Envelope) {
+		if from == nil {
+			return
+		}
+		for name, h := range from.headers {
+			if h.propagate {
+				e.setHeader(name, h)
+			}
+		}
+	}
+}
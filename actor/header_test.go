@@ -0,0 +1,125 @@
+package actor_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+var (
+	traceID  = actor.NewHeaderKey[string]("trace-id").Propagated()
+	tenantID = actor.NewHeaderKey[int]("tenant-id")
+)
+
+// relayActor tells and asks next, overriding the tenant on asks, tells
+// next when started and from future callbacks
+type relayActor struct {
+	next actor.Ref
+}
+
+func (ra *relayActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case *actor.Start:
+		return nil, ctx.Tell(ra.next, ackMsg{})
+	case failMsg:
+		return nil, actor.Fatal(errors.New("boom"))
+	case readyMsg:
+		ctx.AskAsync(ra.next, ackMsg{}).OnComplete(func(actor.Message, error) {
+			_ = ctx.Tell(ra.next, ackMsg{})
+		})
+	case ackMsg:
+		return nil, ctx.Tell(ra.next, msg)
+	case hashMsg:
+		return ctx.Ask(ra.next, ackMsg{}, actor.WithHeader(tenantID, 2), actor.WithHeader(traceID, msg.userID))
+	}
+	return nil, nil
+}
+
+// headersActor reports the headers of the messages it handles
+type headersActor struct {
+	headers chan map[string]any
+}
+
+func (ha *headersActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	if _, ok := msg.(ackMsg); !ok {
+		return nil, nil
+	}
+	headers := map[string]any{}
+	for _, name := range ctx.Envelope().HeaderNames() {
+		headers[name], _ = ctx.Header(name)
+	}
+	ha.headers <- headers
+	return ackMsg{}, nil
+}
+
+func TestHeaders(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	recv := &headersActor{headers: make(chan map[string]any, 10)}
+	recvRef, err := sys.Spawn(recv)
+	require.NoError(t, err)
+	relayRef, err := sys.Spawn(&relayActor{next: recvRef})
+	require.NoError(t, err)
+	require.Empty(t, <-recv.headers)
+
+	require.NoError(t, sys.Tell(recvRef, ackMsg{}, actor.WithHeader(traceID, "t1"), actor.WithHeader(tenantID, 1)))
+	require.Equal(t, map[string]any{"trace-id": "t1", "tenant-id": 1}, <-recv.headers)
+
+	// only propagated headers are passed on
+	require.NoError(t, sys.Tell(relayRef, ackMsg{}, actor.WithHeader(traceID, "t2"), actor.WithHeader(tenantID, 1)))
+	require.Equal(t, map[string]any{"trace-id": "t2"}, <-recv.headers)
+
+	// explicit headers win
+	_, err = sys.Ask(relayRef, hashMsg{userID: "t4"}, actor.WithHeader(traceID, "t3"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"trace-id": "t4", "tenant-id": 2}, <-recv.headers)
+
+	require.NoError(t, sys.Tell(relayRef, ackMsg{}))
+	require.Empty(t, <-recv.headers)
+}
+
+func TestHeaderKey(t *testing.T) {
+	envelope := actor.NewEnvelope(ackMsg{}, actor.WithHeader(tenantID, 7))
+	tenant, ok := tenantID.Get(envelope)
+	require.True(t, ok)
+	require.Equal(t, 7, tenant)
+	_, ok = traceID.Get(envelope)
+	require.False(t, ok)
+	_, ok = tenantID.Get(nil)
+	require.False(t, ok)
+
+	// a key of another type does not match
+	_, ok = actor.NewHeaderKey[string]("tenant-id").Get(envelope)
+	require.False(t, ok)
+}
+
+func TestHeadersNotLeaked(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	recv := &headersActor{headers: make(chan map[string]any, 100)}
+	recvRef, err := sys.Spawn(recv)
+	require.NoError(t, err)
+	relayRef, err := sys.Spawn(&relayActor{next: recvRef})
+	require.NoError(t, err)
+	require.Empty(t, <-recv.headers)
+
+	// restarting handles Start, not the failed message
+	require.NoError(t, sys.Tell(relayRef, failMsg{}, actor.WithHeader(traceID, "t1")))
+	require.Empty(t, <-recv.headers)
+
+	// callbacks on other goroutines send while other messages are handled
+	for i := 0; i < 10; i++ {
+		require.NoError(t, sys.Tell(relayRef, readyMsg{}, actor.WithHeader(traceID, "t2")))
+		require.NoError(t, sys.Tell(relayRef, ackMsg{}, actor.WithHeader(traceID, "t3")))
+	}
+	for i := 0; i < 30; i++ {
+		select {
+		case <-recv.headers:
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
@@ -1,11 +1,20 @@
 package actor
 
+import "context"
+
 type TalkOption func(*Envelope)
 type SpawnOption func(*actor)
 
 type talker interface {
 	Tell(Ref, Message, ...TalkOption) error
 	Ask(Ref, Message, ...TalkOption) (Message, error)
+	AskContext(context.Context, Ref, Message, ...TalkOption) (Message, error)
+	AskAsync(Ref, Message, ...TalkOption) *Future
+}
+
+type namedTalker interface {
+	TellName(string, Message, ...TalkOption) error
+	AskName(string, Message, ...TalkOption) (Message, error)
 }
 
 type referencer interface {
@@ -14,6 +23,8 @@ type referencer interface {
 }
 
 type supervisor interface {
-	Spawn(Actor, ...SpawnOption) Ref
+	Spawn(Actor, ...SpawnOption) (Ref, error)
+	SpawnPool(factory func() Actor, size int, strategy RoutingStrategy, opts ...SpawnOption) (Ref, error)
+	SpawnGroup(refs []Ref, strategy RoutingStrategy, opts ...SpawnOption) (Ref, error)
 	Kill(Ref, bool) error
 }
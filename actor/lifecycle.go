@@ -0,0 +1,76 @@
+package actor
+
+import "runtime/debug"
+
+// PreStarter is called before the actor handles its first message,
+// spawning fails if it returns an error
+type PreStarter interface {
+	PreStart(ctx Context) error
+}
+
+// PostStopper is called after the actor handled its last message
+type PostStopper interface {
+	PostStop(ctx Context)
+}
+
+// PreRestarter is called on the failed instance before the actor is restarted
+type PreRestarter interface {
+	PreRestart(ctx Context, reason error)
+}
+
+// PostRestarter is called on the new instance after the actor was restarted
+type PostRestarter interface {
+	PostRestart(ctx Context, reason error)
+}
+
+// hook runs a lifecycle hook, turning panics into errors
+func hook(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+func (a *actor) preStart(ctx Context) error {
+	h, ok := a.impl.(PreStarter)
+	if !ok {
+		return nil
+	}
+	a.log.Trace("PreStart()")
+	return hook(func() error { return h.PreStart(ctx) })
+}
+
+func (a *actor) postStop(ctx Context) {
+	h, ok := a.impl.(PostStopper)
+	if !ok {
+		return
+	}
+	a.log.Trace("PostStop()")
+	if err := hook(func() error { h.PostStop(ctx); return nil }); err != nil {
+		a.system.reportFailure(&a.ref, err)
+	}
+}
+
+func (a *actor) preRestart(ctx Context, reason error) {
+	h, ok := a.impl.(PreRestarter)
+	if !ok {
+		return
+	}
+	a.log.Trace("PreRestart()")
+	if err := hook(func() error { h.PreRestart(ctx, reason); return nil }); err != nil {
+		a.system.reportFailure(&a.ref, err)
+	}
+}
+
+func (a *actor) postRestart(ctx Context, reason error) {
+	h, ok := a.impl.(PostRestarter)
+	if !ok {
+		return
+	}
+	a.log.Trace("PostRestart()")
+	if err := hook(func() error { h.PostRestart(ctx, reason); return nil }); err != nil {
+		a.system.reportFailure(&a.ref, err)
+	}
+}
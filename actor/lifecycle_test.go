@@ -0,0 +1,94 @@
+package actor_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+// hookedActor reports its lifecycle hooks and messages
+type hookedActor struct {
+	events   chan string
+	preStart error
+}
+
+func (ha *hookedActor) PreStart(ctx actor.Context) error {
+	ha.events <- "pre-start"
+	return ha.preStart
+}
+
+func (ha *hookedActor) PostStop(ctx actor.Context) {
+	ha.events <- "post-stop"
+}
+
+func (ha *hookedActor) PreRestart(ctx actor.Context, reason error) {
+	ha.events <- "pre-restart " + reason.Error()
+}
+
+func (ha *hookedActor) PostRestart(ctx actor.Context, reason error) {
+	ha.events <- "post-restart " + reason.Error()
+}
+
+func (ha *hookedActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg.(type) {
+	case *actor.Start:
+		ha.events <- "start"
+	case *actor.Stop:
+		ha.events <- "stop"
+	case ackMsg:
+		ha.events <- "msg"
+	case failMsg:
+		return nil, actor.Fatal(errors.New("boom"))
+	}
+	return nil, nil
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	a := &hookedActor{events: make(chan string, 10)}
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
+	require.NoError(t, sys.Tell(ref, ackMsg{}))
+	require.NoError(t, sys.Kill(ref, true))
+	for _, event := range []string{"pre-start", "start", "msg", "stop", "post-stop"} {
+		expectEvent(t, a.events, event)
+	}
+}
+
+func TestLifecyclePreStartFails(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	a := &hookedActor{events: make(chan string, 10), preStart: errors.New("nope")}
+	ref, err := sys.Spawn(a, actor.WithName("broken"))
+	require.Error(t, err)
+	require.Nil(t, ref)
+	expectEvent(t, a.events, "pre-start")
+	select {
+	case event := <-a.events:
+		t.Fatalf("unexpected %s", event)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// the name is free again
+	_, err = sys.Spawn(&ackActor{}, actor.WithName("broken"))
+	require.NoError(t, err)
+}
+
+func TestLifecycleRestartHooks(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	events := make(chan string, 10)
+	ref, err := sys.Spawn(&hookedActor{events: events},
+		actor.WithFactory(func() actor.Actor { return &hookedActor{events: events} }))
+	require.NoError(t, err)
+	expectEvent(t, events, "pre-start")
+	expectEvent(t, events, "start")
+	require.NoError(t, sys.Tell(ref, failMsg{}))
+	expectEvent(t, events, "pre-restart boom")
+	expectEvent(t, events, "post-restart boom")
+	expectEvent(t, events, "start")
+}
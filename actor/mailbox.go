@@ -0,0 +1,325 @@
+package actor
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Mailbox queues the envelopes of an actor. Push may be called concurrently,
+// Pop is only called by the actor's loop.
+type Mailbox interface {
+	// Push adds the envelope, returns an error wrapping ErrMailboxOverflow if it was rejected
+	Push(envelope *Envelope) error
+	// Pop removes the next envelope, false if there is none
+	Pop() (*Envelope, bool)
+	// Len returns the amount of queued envelopes
+	Len() int
+	// Ready receives a signal after envelopes were pushed
+	Ready() <-chan struct{}
+}
+
+// DroppingMailbox is implemented by mailboxes that evict queued envelopes
+// to make room for new ones, the actor is notified about every eviction
+type DroppingMailbox interface {
+	Mailbox
+	OnDrop(func(dropped *Envelope))
+}
+
+// MailboxFactory creates the mailboxes of an actor, one for the prioritized
+// messages and one for the others, it is called again whenever a passivated
+// actor is reactivated
+type MailboxFactory func() Mailbox
+
+// DefaultMailboxFactory creates blocking mailboxes of DefaultMailboxSize
+func DefaultMailboxFactory() Mailbox {
+	return NewBlockingMailbox(DefaultMailboxSize)
+}
+
+// Prioritized messages jump the queue, they are handled after
+// system messages but before any other queued message. They are
+// queued in a mailbox of their own with the same capacity and policy.
+type Prioritized interface {
+	Message
+	Prioritized()
+}
+
+// systemMessage is implemented by the messages of the system lane
+type systemMessage interface {
+	Message
+	systemMessage()
+}
+
+// lanes of an actor, envelopes are popped from the unbounded system
+// lane and the priority mailbox before the user mailbox
+type lanes struct {
+	system   Mailbox
+	priority Mailbox
+	// unstashed envelopes are popped before the user mailbox,
+	// only touched by the actor's loop
+	unstashed []*Envelope
+	user      Mailbox
+}
+
+func newLanes(priority Mailbox, user Mailbox) *lanes {
+	return &lanes{
+		system:   NewUnboundedMailbox(),
+		priority: priority,
+		user:     user,
+	}
+}
+
+// push the envelope into the lane matching its message
+func (l *lanes) push(envelope *Envelope) error {
+	switch envelope.msg.(type) {
+	case systemMessage:
+		return l.system.Push(envelope)
+	case Prioritized:
+		return pushTo(l.priority, envelope)
+	}
+	return pushTo(l.user, envelope)
+}
+
+// pushTo the mailbox, without blocking if the envelope must not block
+func pushTo(m Mailbox, envelope *Envelope) error {
+	if t, ok := m.(tryPusher); ok && envelope.nonBlocking {
+		return t.tryPush(envelope)
+	}
+	return m.Push(envelope)
+}
+
+// pop the next envelope by lane priority
+func (l *lanes) pop() (*Envelope, bool) {
+	if envelope, ok := l.system.Pop(); ok {
+		return envelope, true
+	}
+	if envelope, ok := l.priority.Pop(); ok {
+		return envelope, true
+	}
+	if len(l.unstashed) > 0 {
+		envelope := l.unstashed[0]
+		l.unstashed = l.unstashed[1:]
+		return envelope, true
+	}
+	return l.user.Pop()
+}
+
+// len returns the amount of envelopes in all lanes
+func (l *lanes) len() int {
+	return l.system.Len() + l.priority.Len() + len(l.unstashed) + l.user.Len()
+}
+
+// tryPusher is implemented by the mailboxes that can reject envelopes
+// instead of blocking while they are full
+type tryPusher interface {
+	tryPush(envelope *Envelope) error
+}
+
+// signal notifies the consumer without ever blocking producers
+type signal chan struct{}
+
+func newSignal() signal {
+	return make(signal, 1)
+}
+
+func (s signal) notify() {
+	select {
+	case s <- struct{}{}:
+	default:
+	}
+}
+
+func (s signal) Ready() <-chan struct{} {
+	return s
+}
+
+/* unbounded */
+
+type node struct {
+	next     atomic.Pointer[node]
+	envelope *Envelope
+}
+
+// unboundedMailbox is a lock free multi producer single consumer queue
+type unboundedMailbox struct {
+	signal
+	head atomic.Pointer[node]
+	tail *node
+	len  atomic.Int64
+}
+
+// NewUnboundedMailbox creates a lock free mailbox without size limit
+func NewUnboundedMailbox() Mailbox {
+	stub := &node{}
+	m := &unboundedMailbox{
+		signal: newSignal(),
+		tail:   stub,
+	}
+	m.head.Store(stub)
+	return m
+}
+
+func (m *unboundedMailbox) Push(envelope *Envelope) error {
+	n := &node{envelope: envelope}
+	// counted before it can be popped, the length must never go below zero
+	m.len.Add(1)
+	prev := m.head.Swap(n)
+	prev.next.Store(n)
+	m.notify()
+	return nil
+}
+
+func (m *unboundedMailbox) Pop() (*Envelope, bool) {
+	next := m.tail.next.Load()
+	if next == nil {
+		return nil, false
+	}
+	m.tail = next
+	envelope := next.envelope
+	next.envelope = nil
+	m.len.Add(-1)
+	return envelope, true
+}
+
+func (m *unboundedMailbox) Len() int {
+	return int(m.len.Load())
+}
+
+/* bounded */
+
+// boundedMailbox is backed by a buffered channel, what happens
+// when it is full depends on push
+type boundedMailbox struct {
+	signal
+	ch   chan *Envelope
+	push func(*boundedMailbox, *Envelope) error
+}
+
+func newBoundedMailbox(size int, push func(*boundedMailbox, *Envelope) error) *boundedMailbox {
+	if size < 1 {
+		// the loop only pops after being signaled, so there has to be room for one,
+		// sizes below 1 are raised to 1
+		size = 1
+	}
+	return &boundedMailbox{
+		signal: newSignal(),
+		ch:     make(chan *Envelope, size),
+		push:   push,
+	}
+}
+
+func (m *boundedMailbox) Push(envelope *Envelope) error {
+	if err := m.push(m, envelope); err != nil {
+		return err
+	}
+	m.notify()
+	return nil
+}
+
+func (m *boundedMailbox) tryPush(envelope *Envelope) error {
+	select {
+	case m.ch <- envelope:
+	default:
+		return ErrMailboxOverflow
+	}
+	m.notify()
+	return nil
+}
+
+func (m *boundedMailbox) Pop() (*Envelope, bool) {
+	select {
+	case envelope := <-m.ch:
+		return envelope, true
+	default:
+		return nil, false
+	}
+}
+
+func (m *boundedMailbox) Len() int {
+	return len(m.ch)
+}
+
+// NewBlockingMailbox creates a mailbox of the given size, pushing blocks while it is full
+func NewBlockingMailbox(size int) Mailbox {
+	return newBoundedMailbox(size, func(m *boundedMailbox, envelope *Envelope) error {
+		m.ch <- envelope
+		return nil
+	})
+}
+
+// NewDropNewestMailbox creates a mailbox of the given size, envelopes pushed
+// while it is full are rejected
+func NewDropNewestMailbox(size int) Mailbox {
+	return newBoundedMailbox(size, func(m *boundedMailbox, envelope *Envelope) error {
+		select {
+		case m.ch <- envelope:
+			return nil
+		default:
+			return ErrMailboxOverflow
+		}
+	})
+}
+
+// NewTimeoutMailbox creates a mailbox of the given size, pushing blocks while it
+// is full for at most the given timeout before the envelope is rejected
+func NewTimeoutMailbox(size int, timeout time.Duration) Mailbox {
+	return newBoundedMailbox(size, func(m *boundedMailbox, envelope *Envelope) error {
+		select {
+		case m.ch <- envelope:
+			return nil
+		default:
+		}
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case m.ch <- envelope:
+			return nil
+		case <-timer.C:
+			return ErrMailboxOverflow
+		}
+	})
+}
+
+// dropOldestMailbox evicts the oldest envelope to make room for new ones
+type dropOldestMailbox struct {
+	*boundedMailbox
+	onDrop func(*Envelope)
+}
+
+var _ DroppingMailbox = (*dropOldestMailbox)(nil)
+
+// NewDropOldestMailbox creates a mailbox of the given size, pushing while it is
+// full drops the oldest envelope
+func NewDropOldestMailbox(size int) Mailbox {
+	m := &dropOldestMailbox{onDrop: func(*Envelope) {}}
+	m.boundedMailbox = newBoundedMailbox(size, func(b *boundedMailbox, envelope *Envelope) error {
+		for {
+			select {
+			case b.ch <- envelope:
+				return nil
+			default:
+			}
+			select {
+			case dropped := <-b.ch:
+				m.onDrop(dropped)
+			default:
+			}
+		}
+	})
+	return m
+}
+
+// tryPush never blocks anyway, it drops the oldest envelope like Push
+func (m *dropOldestMailbox) tryPush(envelope *Envelope) error {
+	return m.Push(envelope)
+}
+
+func (m *dropOldestMailbox) OnDrop(onDrop func(*Envelope)) {
+	m.onDrop = onDrop
+}
+
+// WithMailboxFactory sets the factory creating the actor's mailbox
+func WithMailboxFactory(factory MailboxFactory) SpawnOption {
+	return func(a *actor) {
+		a.mailboxFactory = factory
+	}
+}
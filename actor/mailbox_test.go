@@ -0,0 +1,246 @@
+package actor_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+func pushN(t *testing.T, mb actor.Mailbox, n int) (envelopes []*actor.Envelope, errs []error) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		e := actor.NewEnvelope(ackMsg{i: i})
+		envelopes = append(envelopes, e)
+		errs = append(errs, mb.Push(e))
+	}
+	return
+}
+
+func popAll(mb actor.Mailbox) (envelopes []*actor.Envelope) {
+	for {
+		e, ok := mb.Pop()
+		if !ok {
+			return
+		}
+		envelopes = append(envelopes, e)
+	}
+}
+
+func TestMailboxUnbounded(t *testing.T) {
+	mb := actor.NewUnboundedMailbox()
+	_, ok := mb.Pop()
+	require.False(t, ok)
+
+	n := 1000
+	wg := sync.WaitGroup{}
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				_ = mb.Push(actor.NewEnvelope(ackMsg{i: i}))
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 4*n, mb.Len())
+	select {
+	case <-mb.Ready():
+	default:
+		t.Fatal("not signaled")
+	}
+	require.Len(t, popAll(mb), 4*n)
+	require.Zero(t, mb.Len())
+}
+
+func TestMailboxBounded(t *testing.T) {
+	// drop newest rejects when full
+	mb := actor.NewDropNewestMailbox(2)
+	envelopes, errs := pushN(t, mb, 3)
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.ErrorIs(t, errs[2], actor.ErrMailboxOverflow)
+	require.Equal(t, envelopes[:2], popAll(mb))
+
+	// drop oldest evicts the head
+	mb = actor.NewDropOldestMailbox(2)
+	dropped := []*actor.Envelope{}
+	mb.(actor.DroppingMailbox).OnDrop(func(e *actor.Envelope) { dropped = append(dropped, e) })
+	envelopes, errs = pushN(t, mb, 3)
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, envelopes[:1], dropped)
+	require.Equal(t, envelopes[1:], popAll(mb))
+
+	// timeout rejects after waiting
+	mb = actor.NewTimeoutMailbox(1, 10*time.Millisecond)
+	start := time.Now()
+	_, errs = pushN(t, mb, 2)
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], actor.ErrMailboxOverflow)
+	require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	// blocking waits for room
+	mb = actor.NewBlockingMailbox(1)
+	_, _ = pushN(t, mb, 1)
+	pushed := make(chan struct{})
+	go func() {
+		_ = mb.Push(actor.NewEnvelope(nil))
+		close(pushed)
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("push did not block")
+	case <-time.After(10 * time.Millisecond):
+	}
+	_, _ = mb.Pop()
+	<-pushed
+	require.Equal(t, 1, mb.Len())
+}
+
+func TestMailboxFactory(t *testing.T) {
+	for name, factory := range map[string]actor.MailboxFactory{
+		"unbounded":   actor.NewUnboundedMailbox,
+		"drop-oldest": func() actor.Mailbox { return actor.NewDropOldestMailbox(10) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			sys := newSystem()
+			defer sys.Stop()
+			a := &ackActor{ack: make(chan ackMsg, 10)}
+			ref, err := sys.Spawn(a, actor.WithMailboxFactory(factory))
+			require.NoError(t, err)
+			for i := 0; i < 5; i++ {
+				require.NoError(t, sys.Tell(ref, ackMsg{i: i}))
+			}
+			for i := 0; i < 5; i++ {
+				select {
+				case ack := <-a.ack:
+					require.Equal(t, i, ack.i)
+				case <-time.After(time.Second):
+					t.Fatal("timeout")
+				}
+			}
+		})
+	}
+}
+
+type gateMsg struct {
+	actor.Message
+	gate chan struct{}
+	// closed once the gate is reached, if set
+	entered chan struct{}
+}
+
+type urgentMsg struct {
+	actor.Message
+	i int
+}
+
+func (urgentMsg) Prioritized() {}
+
+// orderActor records the order of messages, blocking on gates
+type orderActor struct {
+	order chan int
+}
+
+func (oa *orderActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case gateMsg:
+		if msg.entered != nil {
+			close(msg.entered)
+		}
+		<-msg.gate
+	case ackMsg:
+		oa.order <- msg.i
+	case urgentMsg:
+		oa.order <- msg.i
+	case *actor.Stop:
+		close(oa.order)
+	}
+	return nil, nil
+}
+
+func TestMailboxLanes(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	a := &orderActor{order: make(chan int, 10)}
+	ref, err := sys.Spawn(a, actor.WithMailbox(2, false))
+	require.NoError(t, err)
+
+	gate := make(chan struct{})
+	require.NoError(t, sys.Tell(ref, gateMsg{gate: gate}))
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 1}))
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 2}))
+	require.NoError(t, sys.Tell(ref, urgentMsg{i: 100}))
+
+	// graceful stop does not block on the full mailbox
+	killed := make(chan error)
+	go func() { killed <- sys.Kill(ref, true) }()
+	select {
+	case err := <-killed:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("kill blocked")
+	}
+	close(gate)
+
+	// the prioritized message jumps the queue, the queued ones are drained before stopping
+	order := []int{}
+	for i := range a.order {
+		order = append(order, i)
+	}
+	require.Equal(t, []int{100, 1, 2}, order)
+}
+
+func TestMailboxPriorityCapacity(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	a := &orderActor{order: make(chan int, 10)}
+	ref, err := sys.Spawn(a, actor.WithMailbox(1, true))
+	require.NoError(t, err)
+	gate, entered := make(chan struct{}), make(chan struct{})
+	require.NoError(t, sys.Tell(ref, gateMsg{gate: gate, entered: entered}))
+	<-entered
+
+	// prioritized messages are dropped like the others once their lane is full
+	require.NoError(t, sys.Tell(ref, urgentMsg{i: 100}))
+	require.ErrorIs(t, sys.Tell(ref, urgentMsg{i: 101}), actor.ErrMailboxOverflow)
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 1}))
+	require.ErrorIs(t, sys.Tell(ref, ackMsg{i: 2}), actor.ErrMailboxOverflow)
+	close(gate)
+	require.NoError(t, sys.Kill(ref, true))
+	order := []int{}
+	for i := range a.order {
+		order = append(order, i)
+	}
+	require.Equal(t, []int{100, 1}, order)
+}
+
+func TestMailboxBlockedPushAfterStop(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 10)}
+	fwdRef, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+	sys.DeadLetters().Subscribe(fwdRef)
+
+	a := &orderActor{order: make(chan int, 10)}
+	ref, err := sys.Spawn(a, actor.WithMailbox(1, false))
+	require.NoError(t, err)
+	gate, entered := make(chan struct{}), make(chan struct{})
+	require.NoError(t, sys.Tell(ref, gateMsg{gate: gate, entered: entered}))
+	<-entered
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 1}))
+	// blocks until the final drain made room
+	go func() { _ = sys.Tell(ref, ackMsg{i: 2}) }()
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, sys.Kill(ref, false))
+	close(gate)
+	got := []actor.Message{expectDeadLetter(t, fwd.msgs).Msg, expectDeadLetter(t, fwd.msgs).Msg}
+	require.ElementsMatch(t, []actor.Message{ackMsg{i: 1}, ackMsg{i: 2}}, got)
+}
@@ -2,6 +2,8 @@ package actor
 
 import (
 	"fmt"
+	"sync/atomic"
+	"time"
 )
 
 type Message interface {
@@ -12,14 +14,28 @@ type EnvelopeOption = TalkOption
 
 /* implementations */
 
+// the last assigned envelope id
+var envelopeIdx atomic.Uint64
+
 type Envelope struct {
-	sender Ref
-	msg    Message
-	isTell bool
+	id            uint64
+	correlationID uint64
+	sender        Ref
+	msg           Message
+	isTell        bool
+	internal      bool
+	published     bool
+	nonBlocking   bool
+	timer         *timer
+	enqueued      time.Time
+	headers       map[string]header
+	timeout       time.Duration
+	deadline      time.Time
 }
 
 func NewEnvelope(msg Message, opts ...EnvelopeOption) *Envelope {
 	e := &Envelope{
+		id:  envelopeIdx.Add(1),
 		msg: msg,
 	}
 	for _, opt := range opts {
@@ -29,7 +45,17 @@ func NewEnvelope(msg Message, opts ...EnvelopeOption) *Envelope {
 }
 
 func (e *Envelope) String() string {
-	return fmt.Sprintf("sender=%s msg=%T", e.sender, e.msg)
+	return fmt.Sprintf("id=%d correlation=%d sender=%s msg=%T", e.id, e.correlationID, e.sender, e.msg)
+}
+
+// ID is the unique id of the message
+func (e *Envelope) ID() uint64 {
+	return e.id
+}
+
+// CorrelationID is the id of the message this one replies to, 0 if it is no reply
+func (e *Envelope) CorrelationID() uint64 {
+	return e.correlationID
 }
 
 func (e *Envelope) Sender() Ref {
@@ -40,6 +66,26 @@ func (e *Envelope) Msg() Message {
 	return e.msg
 }
 
+// Deadline returns the time the sender stops waiting for a reply, if any
+func (e *Envelope) Deadline() (time.Time, bool) {
+	return e.deadline, !e.deadline.IsZero()
+}
+
+// WithTimeout sets how long Ask waits for the reply, overriding AskTimeout
+func WithTimeout(timeout time.Duration) EnvelopeOption {
+	return func(e *Envelope) {
+		e.timeout = timeout
+	}
+}
+
+// WithCorrelationID marks the message as reply to the message with the given id,
+// use it when replying to an Ask later on via Tell
+func WithCorrelationID(id uint64) EnvelopeOption {
+	return func(e *Envelope) {
+		e.correlationID = id
+	}
+}
+
 func WithSender(sender Ref) EnvelopeOption {
 	return func(e *Envelope) {
 		e.sender = sender
@@ -50,16 +96,38 @@ func Tell(e *Envelope) {
 	e.isTell = true
 }
 
+// internal marks envelopes sent by actors, these are still accepted while
+// the system shuts down
+func internal(e *Envelope) {
+	e.internal = true
+}
+
+// published marks envelopes of the event stream and the dead letter office,
+// failing to deliver them is not published again
+func published(e *Envelope) {
+	e.published = true
+}
+
+// nonBlocking envelopes are rejected instead of waiting while the mailbox is
+// full, unless it is a custom mailbox which is always pushed to
+func nonBlocking(e *Envelope) {
+	e.nonBlocking = true
+}
+
 /* pre defined messages */
 
 type Start struct {
 	Message
 }
 
+func (*Start) systemMessage() {}
+
 type Stop struct {
 	Message
 }
 
+func (*Stop) systemMessage() {}
+
 type Error struct {
 	Message
 	Error error
@@ -0,0 +1,318 @@
+package actor
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsSampleInterval is the interval of the messages whose handling and
+// queueing times are measured, the other ones are only counted
+const MetricsSampleInterval = 8
+
+// the upper bounds of the latency histogram buckets
+var latencyBuckets = [...]time.Duration{
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// Metrics of the actors of a system
+type Metrics interface {
+	// Actors returns the stats of every live actor
+	Actors() []ActorStats
+	// Types returns the stats summed up by actor type, including stopped actors
+	Types() []ActorStats
+}
+
+// ActorStats of an actor or of all actors of a type, then Ref is nil
+type ActorStats struct {
+	Ref           Ref
+	Type          string
+	Received      uint64
+	Replies       uint64
+	Errors        uint64
+	Dropped       uint64
+	MailboxLength int
+	// time spent handling the first and every MetricsSampleInterval-th message
+	Latency Histogram
+	// time messages spent in the mailbox, sampled by MetricsSampleInterval
+	Queued Histogram
+}
+
+// Histogram of sampled durations
+type Histogram struct {
+	// Bounds are the upper bounds of the buckets
+	Bounds []time.Duration
+	// Counts are cumulative, Counts[i] observations were <= Bounds[i]
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// stats are updated lock free on the hot path, only by the actor they
+// belong to but for dropped messages
+type stats struct {
+	received atomic.Uint64
+	replies  atomic.Uint64
+	errors   atomic.Uint64
+	dropped  atomic.Uint64
+	latency  histogram
+	queued   histogram
+}
+
+type histogram struct {
+	buckets [len(latencyBuckets)]atomic.Uint64
+	count   atomic.Uint64
+	sum     atomic.Int64
+}
+
+func (h *histogram) observe(d time.Duration) {
+	for i, bound := range latencyBuckets {
+		if d <= bound {
+			h.buckets[i].Add(1)
+			break
+		}
+	}
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *histogram) snapshot() Histogram {
+	s := Histogram{
+		Bounds: latencyBuckets[:],
+		Counts: make([]uint64, len(latencyBuckets)),
+	}
+	// buckets first, observations made meanwhile must not exceed the count
+	var cumulative uint64
+	for i := range h.buckets {
+		cumulative += h.buckets[i].Load()
+		s.Counts[i] = cumulative
+	}
+	s.Count = max(h.count.Load(), cumulative)
+	s.Sum = time.Duration(h.sum.Load())
+	return s
+}
+
+func (h *histogram) add(o *histogram) {
+	for i := range h.buckets {
+		h.buckets[i].Add(o.buckets[i].Load())
+	}
+	h.count.Add(o.count.Load())
+	h.sum.Add(o.sum.Load())
+}
+
+func (st *stats) add(o *stats) {
+	st.received.Add(o.received.Load())
+	st.replies.Add(o.replies.Load())
+	st.errors.Add(o.errors.Load())
+	st.dropped.Add(o.dropped.Load())
+	st.latency.add(&o.latency)
+	st.queued.add(&o.queued)
+}
+
+func (st *stats) snapshot(ref Ref, typ string) ActorStats {
+	return ActorStats{
+		Ref:      ref,
+		Type:     typ,
+		Received: st.received.Load(),
+		Replies:  st.replies.Load(),
+		Errors:   st.errors.Load(),
+		Dropped:  st.dropped.Load(),
+		Latency:  st.latency.snapshot(),
+		Queued:   st.queued.snapshot(),
+	}
+}
+
+/* recording, per actor only, types are summed up when read */
+
+// sampleEnqueued stamps the envelopes whose time in the mailbox is measured
+func sampleEnqueued(envelope *Envelope) {
+	if envelope.id%MetricsSampleInterval == 0 {
+		envelope.enqueued = time.Now()
+	}
+}
+
+// recordReceived counts the envelope and tells whether its handling is timed
+func (a *actor) recordReceived(envelope *Envelope) (timed bool) {
+	n := a.stats.received.Add(1)
+	if !envelope.enqueued.IsZero() {
+		a.stats.queued.observe(time.Since(envelope.enqueued))
+	}
+	return (n-1)%MetricsSampleInterval == 0
+}
+
+// recordHandled records the outcome, timed since start unless it is zero
+func (a *actor) recordHandled(start time.Time, err error) {
+	if !start.IsZero() {
+		a.stats.latency.observe(time.Since(start))
+	}
+	if err != nil {
+		a.stats.errors.Add(1)
+	}
+}
+
+func (a *actor) recordReply() {
+	a.stats.replies.Add(1)
+}
+
+func (a *actor) recordDropped() {
+	a.stats.dropped.Add(1)
+}
+
+/* system metrics */
+
+var _ Metrics = (*systemMetrics)(nil)
+
+type systemMetrics struct {
+	system *system
+}
+
+// Metrics of the system's actors
+func (s *system) Metrics() Metrics {
+	return systemMetrics{system: s}
+}
+
+func (m systemMetrics) Actors() []ActorStats {
+	s := m.system
+	s.lock.RLock()
+	actors := make([]*actor, 0, len(s.actors))
+	for _, a := range s.actors {
+		actors = append(actors, a)
+	}
+	s.lock.RUnlock()
+	slices.SortFunc(actors, func(a, b *actor) int { return cmp.Compare(a.ref.id, b.ref.id) })
+	all := make([]ActorStats, 0, len(actors))
+	for _, a := range actors {
+		st := a.stats.snapshot(&a.ref, typeName(a.typ))
+		st.MailboxLength = a.mailboxLen()
+		all = append(all, st)
+	}
+	return all
+}
+
+func (m systemMetrics) Types() []ActorStats {
+	s := m.system
+	types := map[string]*stats{}
+	sum := func(typ reflect.Type, st *stats) {
+		total, ok := types[typeName(typ)]
+		if !ok {
+			total = &stats{}
+			types[typeName(typ)] = total
+		}
+		total.add(st)
+	}
+	s.lock.RLock()
+	for typ, st := range s.typeStats {
+		sum(typ, st)
+	}
+	for _, a := range s.actors {
+		sum(a.typ, a.stats)
+	}
+	for _, a := range s.stopping {
+		sum(a.typ, a.stats)
+	}
+	s.lock.RUnlock()
+	all := make([]ActorStats, 0, len(types))
+	for typ, st := range types {
+		all = append(all, st.snapshot(nil, typ))
+	}
+	slices.SortFunc(all, func(a, b ActorStats) int { return strings.Compare(a.Type, b.Type) })
+	return all
+}
+
+// typeName labels the metrics of actors of typ
+func typeName(typ reflect.Type) string {
+	if typ == nil {
+		return "<nil>"
+	}
+	return typ.String()
+}
+
+// statsOf returns the stats of the stopped actors of typ, lock must be held
+func (s *system) statsOf(typ reflect.Type) *stats {
+	st, ok := s.typeStats[typ]
+	if !ok {
+		st = &stats{}
+		s.typeStats[typ] = st
+	}
+	return st
+}
+
+/* prometheus exposition */
+
+// MetricsHandler serves the metrics in the Prometheus text format
+func MetricsHandler(m Metrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WritePrometheus(w, m)
+	})
+}
+
+// WritePrometheus writes the metrics in the Prometheus text format
+func WritePrometheus(w io.Writer, m Metrics) {
+	for _, scope := range []struct {
+		prefix string
+		stats  []ActorStats
+	}{
+		{"actress_actor_", m.Actors()},
+		{"actress_type_", m.Types()},
+	} {
+		writeCounter(w, scope.prefix+"messages_received_total", "Messages handled.", scope.stats, func(s ActorStats) uint64 { return s.Received })
+		writeCounter(w, scope.prefix+"replies_total", "Replies sent.", scope.stats, func(s ActorStats) uint64 { return s.Replies })
+		writeCounter(w, scope.prefix+"errors_total", "Messages handled with an error.", scope.stats, func(s ActorStats) uint64 { return s.Errors })
+		writeCounter(w, scope.prefix+"messages_dropped_total", "Messages rejected or dropped by a full mailbox.", scope.stats, func(s ActorStats) uint64 { return s.Dropped })
+		if scope.prefix == "actress_actor_" {
+			name := scope.prefix + "mailbox_length"
+			fmt.Fprintf(w, "# HELP %s Messages waiting in the mailbox.\n# TYPE %s gauge\n", name, name)
+			for _, s := range scope.stats {
+				fmt.Fprintf(w, "%s{%s} %d\n", name, labels(s, ""), s.MailboxLength)
+			}
+		}
+		writeHistogram(w, scope.prefix+"handler_duration_seconds", "Time spent handling messages.", scope.stats, func(s ActorStats) Histogram { return s.Latency })
+		writeHistogram(w, scope.prefix+"queued_duration_seconds", "Time messages spent in the mailbox.", scope.stats, func(s ActorStats) Histogram { return s.Queued })
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labels(s ActorStats, extra string) string {
+	l := fmt.Sprintf(`type="%s"`, labelEscaper.Replace(s.Type))
+	if s.Ref != nil {
+		l = fmt.Sprintf(`actor="%s",%s`, labelEscaper.Replace(s.Ref.String()), l)
+	}
+	if extra != "" {
+		l += "," + extra
+	}
+	return l
+}
+
+func writeCounter(w io.Writer, name, help string, stats []ActorStats, value func(ActorStats) uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, labels(s, ""), value(s))
+	}
+}
+
+func writeHistogram(w io.Writer, name, help string, stats []ActorStats, value func(ActorStats) Histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, s := range stats {
+		h := value(s)
+		for i, bound := range h.Bounds {
+			fmt.Fprintf(w, "%s_bucket{%s} %d\n", name, labels(s, fmt.Sprintf(`le="%g"`, bound.Seconds())), h.Counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s} %d\n", name, labels(s, `le="+Inf"`), h.Count)
+		fmt.Fprintf(w, "%s_sum{%s} %g\n", name, labels(s, ""), h.Sum.Seconds())
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels(s, ""), h.Count)
+	}
+}
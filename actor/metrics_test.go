@@ -0,0 +1,90 @@
+package actor_test
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+func TestMetrics(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&lifecycleActor{events: make(chan string, 10)})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := sys.Ask(ref, ackMsg{})
+		require.NoError(t, err)
+	}
+
+	// full mailbox
+	a := &orderActor{order: make(chan int, 10)}
+	blocked, err := sys.Spawn(a, actor.WithMailbox(1, true))
+	require.NoError(t, err)
+	gate := make(chan struct{})
+	defer close(gate)
+	require.NoError(t, sys.Tell(blocked, gateMsg{gate: gate}))
+	require.Eventually(t, func() bool {
+		return sys.Tell(blocked, ackMsg{}) != nil
+	}, time.Second, time.Millisecond)
+
+	stats := sys.Metrics().Actors()
+	require.Len(t, stats, 2)
+	require.Equal(t, ref.String(), stats[0].Ref.String())
+	// Start and the asks
+	require.Equal(t, uint64(4), stats[0].Received)
+	require.Equal(t, uint64(3), stats[0].Replies)
+	// only the first of them is timed
+	require.Equal(t, uint64(1), stats[0].Latency.Count)
+	require.Equal(t, uint64(1), stats[0].Latency.Counts[len(stats[0].Latency.Counts)-1])
+	require.Equal(t, 1, stats[1].MailboxLength)
+	require.Equal(t, uint64(1), stats[1].Dropped)
+
+	// types are kept after the actors stopped
+	require.NoError(t, sys.Kill(ref, true))
+	require.Eventually(t, func() bool { return len(sys.Metrics().Actors()) == 1 }, time.Second, time.Millisecond)
+	// and summed up with the live ones
+	other, err := sys.Spawn(&lifecycleActor{events: make(chan string, 10)})
+	require.NoError(t, err)
+	_, err = sys.Ask(other, ackMsg{})
+	require.NoError(t, err)
+	types := sys.Metrics().Types()
+	require.Len(t, types, 2)
+	require.Equal(t, "*actor_test.lifecycleActor", types[0].Type)
+	require.Equal(t, uint64(4), types[0].Replies)
+
+	// prometheus text
+	srv := httptest.NewServer(actor.MetricsHandler(sys.Metrics()))
+	defer srv.Close()
+	res, err := srv.Client().Get(srv.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "# TYPE actress_actor_messages_dropped_total counter\n")
+	require.Contains(t, string(body), fmt.Sprintf("actress_actor_messages_dropped_total{actor=%q,type=\"*actor_test.orderActor\"} 1\n", blocked.String()))
+	require.Contains(t, string(body), fmt.Sprintf("actress_actor_mailbox_length{actor=%q,type=\"*actor_test.orderActor\"} 1\n", blocked.String()))
+	require.Contains(t, string(body), "actress_type_replies_total{type=\"*actor_test.lifecycleActor\"} 4\n")
+	require.Contains(t, string(body), "actress_type_handler_duration_seconds_bucket{type=\"*actor_test.lifecycleActor\",le=\"+Inf\"} 2\n")
+}
+
+func TestMetricsFactoryOnly(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	_, err := sys.Spawn(nil)
+	require.ErrorIs(t, err, actor.ErrActorNotImplemented)
+
+	// the type comes from the factory's instance
+	events := make(chan string, 10)
+	ref, err := sys.Spawn(nil, actor.WithFactory(func() actor.Actor { return &lifecycleActor{events: events} }))
+	require.NoError(t, err)
+	expectEvent(t, events, "start")
+	stats := sys.Metrics().Actors()
+	require.Len(t, stats, 1)
+	require.Equal(t, ref.String(), stats[0].Ref.String())
+	require.Equal(t, "*actor_test.lifecycleActor", stats[0].Type)
+}
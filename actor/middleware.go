@@ -0,0 +1,74 @@
+package actor
+
+import "sync"
+
+// Middleware wraps the handling of messages, the envelope is available via Context.Envelope
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// SendFunc sends an envelope to whom
+type SendFunc func(whom Ref, envelope *Envelope) error
+
+// SendMiddleware wraps sending envelopes, e.g. to add headers
+type SendMiddleware func(next SendFunc) SendFunc
+
+// WithMiddleware wraps the actor's handling of messages, after the system's middleware
+func WithMiddleware(middleware ...Middleware) SpawnOption {
+	return func(a *actor) {
+		a.middleware = append(a.middleware, middleware...)
+	}
+}
+
+// middlewares of the system
+type middlewares struct {
+	lock    sync.RWMutex
+	handle  []Middleware
+	send    []SendMiddleware
+	sending SendFunc
+}
+
+// UseMiddleware adds middleware wrapping the handling of all actors spawned afterwards,
+// the first one added is the outermost
+func (s *system) UseMiddleware(middleware ...Middleware) {
+	s.middlewares.lock.Lock()
+	defer s.middlewares.lock.Unlock()
+	s.middlewares.handle = append(s.middlewares.handle, middleware...)
+}
+
+// UseSendMiddleware adds middleware wrapping every envelope sent, the first one
+// added is the outermost
+func (s *system) UseSendMiddleware(middleware ...SendMiddleware) {
+	s.middlewares.lock.Lock()
+	defer s.middlewares.lock.Unlock()
+	s.middlewares.send = append(s.middlewares.send, middleware...)
+	send := s.transmit
+	for i := len(s.middlewares.send) - 1; i >= 0; i-- {
+		send = s.middlewares.send[i](send)
+	}
+	s.middlewares.sending = send
+}
+
+// handleMiddleware returns the system's middleware
+func (s *system) handleMiddleware() []Middleware {
+	s.middlewares.lock.RLock()
+	defer s.middlewares.lock.RUnlock()
+	return s.middlewares.handle
+}
+
+// sendEnvelope sends the envelope through the send middleware
+func (s *system) sendEnvelope(whom Ref, envelope *Envelope) error {
+	s.middlewares.lock.RLock()
+	send := s.middlewares.sending
+	s.middlewares.lock.RUnlock()
+	if send == nil {
+		return s.transmit(whom, envelope)
+	}
+	return send(whom, envelope)
+}
+
+// chain wraps handler with the middleware, the first one is the outermost
+func chain(handler HandlerFunc, middleware ...Middleware) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,129 @@
+package actor_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+// recorder records the middleware calls
+type recorder struct {
+	lock  sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(call string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) middleware(name string) actor.Middleware {
+	return func(next actor.HandlerFunc) actor.HandlerFunc {
+		return func(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+			if _, ok := msg.(ackMsg); ok {
+				r.record(fmt.Sprintf("%s %d", name, ctx.Envelope().ID()))
+			}
+			return next(ctx, msg)
+		}
+	}
+}
+
+var errUnauthorized = errors.New("unauthorized")
+
+// onlyAcks rejects everything but ackMsg
+func onlyAcks(next actor.HandlerFunc) actor.HandlerFunc {
+	return func(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+		switch msg.(type) {
+		case ackMsg, *actor.Start, *actor.Stop:
+			return next(ctx, msg)
+		}
+		return nil, errUnauthorized
+	}
+}
+
+// recoverPanics turns panics into errors replied to the sender
+func recoverPanics(next actor.HandlerFunc) actor.HandlerFunc {
+	return func(ctx actor.Context, msg actor.Message) (reply actor.Message, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("recovered: %v", r)
+			}
+		}()
+		return next(ctx, msg)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	rec := &recorder{}
+	sys.UseMiddleware(rec.middleware("system"))
+	ref, err := sys.Spawn(&ackActor{ack: make(chan ackMsg, 10)}, actor.WithMiddleware(rec.middleware("actor"), onlyAcks))
+	require.NoError(t, err)
+
+	// the system's middleware runs first, the envelope is available
+	_, err = sys.Ask(ref, ackMsg{i: 1})
+	require.NoError(t, err)
+	require.Len(t, rec.calls, 2)
+	require.Regexp(t, `^system \d+$`, rec.calls[0])
+	require.Equal(t, "actor"+rec.calls[0][len("system"):], rec.calls[1])
+
+	// middleware can reject
+	reply, err := sys.Ask(ref, gateMsg{})
+	require.NoError(t, err)
+	require.IsType(t, &actor.Error{}, reply)
+	require.ErrorIs(t, reply.(*actor.Error).Error, errUnauthorized)
+}
+
+func TestMiddlewareRecover(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	failures := make(chan error, 10)
+	sys.SetErrorSink(func(ref actor.Ref, err error) { failures <- err })
+	ref, err := sys.Spawn(&panicActor{}, actor.WithMiddleware(recoverPanics))
+	require.NoError(t, err)
+
+	reply, err := sys.Ask(ref, panicMsg{})
+	require.NoError(t, err)
+	require.IsType(t, &actor.Error{}, reply)
+	require.EqualError(t, reply.(*actor.Error).Error, "recovered: oh no")
+	reply, err = sys.Ask(ref, ackMsg{})
+	require.NoError(t, err)
+	require.Equal(t, ackMsg{i: 1}, reply)
+	// no failure to supervise
+	require.Empty(t, failures)
+}
+
+func TestSendMiddleware(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	a := &ackActor{ack: make(chan ackMsg, 10)}
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
+
+	rec := &recorder{}
+	sys.UseSendMiddleware(func(next actor.SendFunc) actor.SendFunc {
+		return func(whom actor.Ref, envelope *actor.Envelope) error {
+			if ack, ok := envelope.Msg().(ackMsg); ok {
+				rec.record(fmt.Sprintf("%s %d", whom, ack.i))
+			}
+			return next(whom, envelope)
+		}
+	}, func(next actor.SendFunc) actor.SendFunc {
+		return func(whom actor.Ref, envelope *actor.Envelope) error {
+			if ack, ok := envelope.Msg().(ackMsg); ok && ack.i < 0 {
+				return errUnauthorized
+			}
+			return next(whom, envelope)
+		}
+	})
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 1}))
+	require.ErrorIs(t, sys.Tell(ref, ackMsg{i: -1}), errUnauthorized)
+	require.Equal(t, []string{ref.String() + " 1", ref.String() + " -1"}, rec.calls)
+	require.Equal(t, ackMsg{i: 1}, <-a.ack)
+}
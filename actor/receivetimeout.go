@@ -0,0 +1,54 @@
+package actor
+
+import "time"
+
+// ReceiveTimeout is handled when the actor received no user message
+// for the duration set with Context.SetReceiveTimeout
+type ReceiveTimeout struct {
+	Message
+}
+
+func (*ReceiveTimeout) systemMessage() {}
+
+// receiveTimer fires after the receive timeout, only touched by the loop
+type receiveTimer struct {
+	timeout time.Duration
+	timer   *time.Timer
+	changed bool
+}
+
+func (r *receiveTimer) set(timeout time.Duration) {
+	r.timeout = timeout
+	r.changed = true
+}
+
+// C is nil while there is no receive timeout
+func (r *receiveTimer) C() <-chan time.Time {
+	if r.timer == nil || r.timeout <= 0 {
+		return nil
+	}
+	return r.timer.C
+}
+
+// handled restarts the timer after a user message or if the timeout changed
+func (r *receiveTimer) handled(user bool) {
+	if !user && !r.changed {
+		return
+	}
+	r.changed = false
+	if r.timeout <= 0 {
+		r.stop()
+		return
+	}
+	if r.timer == nil {
+		r.timer = time.NewTimer(r.timeout)
+		return
+	}
+	r.timer.Reset(r.timeout)
+}
+
+func (r *receiveTimer) stop() {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+}
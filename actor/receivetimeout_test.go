@@ -0,0 +1,61 @@
+package actor_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+// sessionActor expires after being idle, ackMsg 0 disables the expiry
+type sessionActor struct {
+	timeout  time.Duration
+	timeouts chan time.Time
+}
+
+func (sa *sessionActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case *actor.Start:
+		ctx.SetReceiveTimeout(sa.timeout)
+	case *actor.ReceiveTimeout:
+		sa.timeouts <- time.Now()
+	case ackMsg:
+		if msg.i == 0 {
+			ctx.SetReceiveTimeout(0)
+		}
+		return msg, nil
+	}
+	return nil, nil
+}
+
+func TestReceiveTimeout(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	timeout := 100 * time.Millisecond
+	a := &sessionActor{timeout: timeout, timeouts: make(chan time.Time, 10)}
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
+
+	// every message resets the timeout, messages are far more frequent than the timeout
+	var last atomic.Int64
+	require.Never(t, func() bool {
+		last.Store(time.Now().UnixNano())
+		return sys.Tell(ref, ackMsg{i: 1}) != nil || len(a.timeouts) > 0
+	}, 3*timeout, 5*time.Millisecond)
+	select {
+	case at := <-a.timeouts:
+		require.GreaterOrEqual(t, at.Sub(time.Unix(0, last.Load())), timeout)
+	case <-time.After(10 * timeout):
+		t.Fatal("no receive timeout")
+	}
+
+	// cancelled, timeouts before were handled before the reply
+	_, err = sys.Ask(ref, ackMsg{i: 0})
+	require.NoError(t, err)
+	for len(a.timeouts) > 0 {
+		<-a.timeouts
+	}
+	require.Never(t, func() bool { return len(a.timeouts) > 0 }, 3*timeout, time.Millisecond)
+}
@@ -2,7 +2,6 @@ package actor
 
 import (
 	"fmt"
-	"time"
 )
 
 // Ref to an actor, might be local, remote or cluster
@@ -28,21 +27,24 @@ func (lr *localRef) String() string {
 	return fmt.Sprintf("local#%d", lr.id)
 }
 
-/* channel ref */
+/* future ref */
 
-type channelRef struct {
-	Ref
-	id int64
-	ch chan *Envelope
+var _ Ref = (*futureRef)(nil)
+
+// futureRef completes its future with the first reply to the request
+type futureRef struct {
+	id        uint64
+	requestID uint64
+	future    *Future
 }
 
-func newChannelRef(ch chan *Envelope) channelRef {
-	return channelRef{
-		id: time.Now().UnixNano(),
-		ch: ch,
+func newFutureRef(id uint64, future *Future) futureRef {
+	return futureRef{
+		id:     id,
+		future: future,
 	}
 }
 
-func (cr *channelRef) String() string {
-	return fmt.Sprintf("channel#%d", cr.id)
+func (fr *futureRef) String() string {
+	return fmt.Sprintf("future#%d", fr.id)
 }
@@ -0,0 +1,360 @@
+package actor
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// RoutingStrategy picks the routees a message is delivered to, a strategy
+// can be passed to several routers, each router routes with its own copy
+type RoutingStrategy interface {
+	route(msg Message, routees []*actor) []*actor
+	// instance returns a copy without the state of other routers
+	instance() RoutingStrategy
+}
+
+// routeeObserver strategies are told the routees whenever they change
+type routeeObserver interface {
+	routeesChanged(routees []*actor)
+}
+
+// RoundRobin routes to the routees in turn
+func RoundRobin() RoutingStrategy {
+	return &roundRobin{}
+}
+
+type roundRobin struct {
+	next atomic.Uint64
+}
+
+func (rr *roundRobin) instance() RoutingStrategy {
+	return &roundRobin{}
+}
+
+func (rr *roundRobin) route(_ Message, routees []*actor) []*actor {
+	i := rr.next.Add(1) - 1
+	return routees[i%uint64(len(routees)) : i%uint64(len(routees))+1]
+}
+
+// Random routes to a random routee
+func Random() RoutingStrategy {
+	return random{}
+}
+
+type random struct{}
+
+func (r random) instance() RoutingStrategy {
+	return r
+}
+
+func (random) route(_ Message, routees []*actor) []*actor {
+	i := rand.IntN(len(routees))
+	return routees[i : i+1]
+}
+
+// Broadcast routes to all routees
+func Broadcast() RoutingStrategy {
+	return broadcast{}
+}
+
+type broadcast struct{}
+
+func (b broadcast) instance() RoutingStrategy {
+	return b
+}
+
+func (broadcast) route(_ Message, routees []*actor) []*actor {
+	return routees
+}
+
+// SmallestMailbox routes to the routee with the least envelopes queued
+func SmallestMailbox() RoutingStrategy {
+	return smallestMailbox{}
+}
+
+type smallestMailbox struct{}
+
+func (sm smallestMailbox) instance() RoutingStrategy {
+	return sm
+}
+
+func (smallestMailbox) route(_ Message, routees []*actor) []*actor {
+	smallest, size := 0, -1
+	for i, r := range routees {
+		n := r.mailboxLen()
+		if size < 0 || n < size {
+			smallest, size = i, n
+			if n == 0 {
+				break
+			}
+		}
+	}
+	return routees[smallest : smallest+1]
+}
+
+/* router management messages, handled by the router itself */
+
+type routerMessage interface {
+	routerMessage()
+}
+
+// Resize the pool to the given amount of routees
+type Resize struct {
+	Message
+	Size int
+}
+
+func (*Resize) routerMessage() {}
+
+// AddRoutee adds an existing actor to a group
+type AddRoutee struct {
+	Message
+	Ref Ref
+}
+
+func (*AddRoutee) routerMessage() {}
+
+// RemoveRoutee removes an actor from a group, it keeps running
+type RemoveRoutee struct {
+	Message
+	Ref Ref
+}
+
+func (*RemoveRoutee) routerMessage() {}
+
+// GetRoutees asks the router for its Routees
+type GetRoutees struct {
+	Message
+}
+
+func (*GetRoutees) routerMessage() {}
+
+// Routees is the reply to GetRoutees
+type Routees struct {
+	Message
+	Refs []Ref
+}
+
+/* router */
+
+// router delivers envelopes sent to its actor to the routees
+type router struct {
+	strategy RoutingStrategy
+
+	lock    sync.RWMutex
+	routees []*actor
+}
+
+func newRouter(strategy RoutingStrategy) *router {
+	return &router{strategy: strategy.instance()}
+}
+
+// route the envelope to the routees picked by the strategy
+func (r *router) route(s *system, whom Ref, envelope *Envelope) error {
+	r.lock.RLock()
+	if len(r.routees) == 0 {
+		r.lock.RUnlock()
+		s.deadLetter(whom, envelope, ErrNoRoutees(whom))
+		return ErrNoRoutees(whom)
+	}
+	targets := r.strategy.route(envelope.msg, r.routees)
+	r.lock.RUnlock()
+	if len(targets) == 0 {
+		s.deadLetter(whom, envelope, ErrUnroutable(whom))
+		return ErrUnroutable(whom)
+	}
+	var err error
+	for _, target := range targets {
+		if len(targets) > 1 {
+			// every routee gets its own copy
+			copied := *envelope
+			envelope = &copied
+		}
+		// routees might be routers themselves, failures are dead-lettered
+		err = s.transmit(&target.ref, envelope)
+	}
+	return err
+}
+
+func (r *router) add(a *actor) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.routees = append(r.routees, a)
+	r.changed()
+}
+
+func (r *router) remove(ref Ref) bool {
+	lref, ok := ref.(*localRef)
+	if !ok {
+		return false
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	n := len(r.routees)
+	r.routees = slices.DeleteFunc(r.routees, func(a *actor) bool { return a.ref == *lref })
+	if len(r.routees) == n {
+		return false
+	}
+	r.changed()
+	return true
+}
+
+// changed tells the strategy about the new routees, lock must be held
+func (r *router) changed() {
+	if o, ok := r.strategy.(routeeObserver); ok {
+		o.routeesChanged(r.routees)
+	}
+}
+
+func (r *router) refs() []Ref {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	refs := make([]Ref, len(r.routees))
+	for i, a := range r.routees {
+		refs[i] = &a.ref
+	}
+	return refs
+}
+
+// withRouter makes the actor route what it is sent
+func withRouter(r *router) SpawnOption {
+	return func(a *actor) {
+		a.router = r
+	}
+}
+
+/* pool */
+
+// poolRouter spawns and supervises its routees, crashed ones are replaced
+type poolRouter struct {
+	system  *system
+	router  *router
+	factory func() Actor
+	size    int
+}
+
+var (
+	_ Actor      = (*poolRouter)(nil)
+	_ PreStarter = (*poolRouter)(nil)
+)
+
+// SpawnPool spawns a router with size routees created by factory, restarted
+// routees get a fresh instance from factory as well
+func (s *system) SpawnPool(factory func() Actor, size int, strategy RoutingStrategy, opts ...SpawnOption) (Ref, error) {
+	return s.spawnPool(nil, factory, size, strategy, opts...)
+}
+
+func (s *system) spawnPool(parent Ref, factory func() Actor, size int, strategy RoutingStrategy, opts ...SpawnOption) (Ref, error) {
+	r := newRouter(strategy)
+	pool := &poolRouter{system: s, router: r, factory: factory, size: size}
+	return s.spawn(pool, parent, append(opts, withRouter(r))...)
+}
+
+func (p *poolRouter) PreStart(ctx Context) error {
+	return p.resize(ctx, p.size)
+}
+
+func (p *poolRouter) Handle(ctx Context, msg Message) (Message, error) {
+	switch msg := msg.(type) {
+	case *Resize:
+		return nil, p.resize(ctx, msg.Size)
+	case *GetRoutees:
+		return &Routees{Refs: p.router.refs()}, nil
+	case *Terminated:
+		if p.router.remove(msg.Ref) && !p.system.closing.Load() {
+			// replace the crashed routee
+			return nil, p.spawnRoutee(ctx)
+		}
+	}
+	return nil, nil
+}
+
+// resize spawns or gracefully stops routees until there are size
+func (p *poolRouter) resize(ctx Context, size int) error {
+	p.size = size
+	refs := p.router.refs()
+	for i := len(refs); i < size; i++ {
+		if err := p.spawnRoutee(ctx); err != nil {
+			return err
+		}
+	}
+	for i := len(refs) - 1; i >= size && i >= 0; i-- {
+		_ = ctx.Unwatch(refs[i])
+		p.router.remove(refs[i])
+		_ = ctx.Kill(refs[i], true)
+	}
+	return nil
+}
+
+func (p *poolRouter) spawnRoutee(ctx Context) error {
+	ref, err := ctx.Spawn(p.factory(), WithFactory(p.factory))
+	if err != nil {
+		return err
+	}
+	if routee, ok := p.system.local(ref); ok {
+		p.router.add(routee)
+	}
+	// replaced once it terminates
+	return ctx.Watch(ref)
+}
+
+/* group */
+
+// groupRouter routes to existing actors, terminated ones are removed
+type groupRouter struct {
+	system *system
+	router *router
+	refs   []Ref
+}
+
+var (
+	_ Actor      = (*groupRouter)(nil)
+	_ PreStarter = (*groupRouter)(nil)
+)
+
+// SpawnGroup spawns a router over existing actors
+func (s *system) SpawnGroup(refs []Ref, strategy RoutingStrategy, opts ...SpawnOption) (Ref, error) {
+	return s.spawnGroup(nil, refs, strategy, opts...)
+}
+
+func (s *system) spawnGroup(parent Ref, refs []Ref, strategy RoutingStrategy, opts ...SpawnOption) (Ref, error) {
+	r := newRouter(strategy)
+	group := &groupRouter{system: s, router: r, refs: refs}
+	return s.spawn(group, parent, append(opts, withRouter(r))...)
+}
+
+func (g *groupRouter) PreStart(ctx Context) error {
+	for _, ref := range g.refs {
+		if err := g.add(ctx, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *groupRouter) Handle(ctx Context, msg Message) (Message, error) {
+	switch msg := msg.(type) {
+	case *AddRoutee:
+		return nil, g.add(ctx, msg.Ref)
+	case *RemoveRoutee:
+		_ = ctx.Unwatch(msg.Ref)
+		g.router.remove(msg.Ref)
+	case *GetRoutees:
+		return &Routees{Refs: g.router.refs()}, nil
+	case *Terminated:
+		g.router.remove(msg.Ref)
+	}
+	return nil, nil
+}
+
+func (g *groupRouter) add(ctx Context, ref Ref) error {
+	routee, ok := g.system.local(ref)
+	if !ok {
+		return ErrActorNotFound(ref)
+	}
+	g.router.add(routee)
+	// removed once it terminates
+	return ctx.Watch(ref)
+}
@@ -0,0 +1,150 @@
+package actor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+// routeeActor replies with its ref, blocking on gates
+type routeeActor struct {
+	seen chan actor.Ref
+}
+
+func (ra *routeeActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case gateMsg:
+		<-msg.gate
+	case ackMsg, hashMsg:
+		if ra.seen != nil {
+			ra.seen <- ctx.Self()
+		}
+		return childRef{Ref: ctx.Self()}, nil
+	}
+	return nil, nil
+}
+
+func routees(t *testing.T, sys actor.System, pool actor.Ref) []actor.Ref {
+	t.Helper()
+	reply, err := sys.Ask(pool, &actor.GetRoutees{})
+	require.NoError(t, err)
+	require.IsType(t, &actor.Routees{}, reply)
+	return reply.(*actor.Routees).Refs
+}
+
+func askRoutee(t *testing.T, sys actor.System, pool actor.Ref) string {
+	t.Helper()
+	reply, err := sys.Ask(pool, ackMsg{})
+	require.NoError(t, err)
+	require.IsType(t, childRef{}, reply)
+	return reply.(childRef).Ref.String()
+}
+
+func TestPoolRoundRobin(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	pool, err := sys.SpawnPool(func() actor.Actor { return &routeeActor{} }, 3, actor.RoundRobin())
+	require.NoError(t, err)
+	refs := routees(t, sys, pool)
+	require.Len(t, refs, 3)
+	for i := 0; i < 6; i++ {
+		require.Equal(t, refs[i%3].String(), askRoutee(t, sys, pool))
+	}
+}
+
+func TestPoolRandom(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	pool, err := sys.SpawnPool(func() actor.Actor { return &routeeActor{} }, 3, actor.Random())
+	require.NoError(t, err)
+	refs := []string{}
+	for _, ref := range routees(t, sys, pool) {
+		refs = append(refs, ref.String())
+	}
+	for i := 0; i < 10; i++ {
+		require.Contains(t, refs, askRoutee(t, sys, pool))
+	}
+}
+
+func TestPoolBroadcast(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	seen := make(chan actor.Ref, 10)
+	pool, err := sys.SpawnPool(func() actor.Actor { return &routeeActor{seen: seen} }, 3, actor.Broadcast())
+	require.NoError(t, err)
+	require.NoError(t, sys.Tell(pool, ackMsg{}))
+	got := []string{}
+	for range 3 {
+		select {
+		case ref := <-seen:
+			got = append(got, ref.String())
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	expected := []string{}
+	for _, ref := range routees(t, sys, pool) {
+		expected = append(expected, ref.String())
+	}
+	require.ElementsMatch(t, expected, got)
+}
+
+func TestPoolSmallestMailbox(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	pool, err := sys.SpawnPool(func() actor.Actor { return &routeeActor{} }, 2, actor.SmallestMailbox())
+	require.NoError(t, err)
+	refs := routees(t, sys, pool)
+
+	// keep the first routee busy with a queued message
+	gate := make(chan struct{})
+	defer close(gate)
+	require.NoError(t, sys.Tell(refs[0], gateMsg{gate: gate}))
+	require.NoError(t, sys.Tell(refs[0], ackMsg{}))
+	for i := 0; i < 3; i++ {
+		require.Equal(t, refs[1].String(), askRoutee(t, sys, pool))
+	}
+}
+
+func TestPoolResizeAndReplace(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	pool, err := sys.SpawnPool(func() actor.Actor { return &routeeActor{} }, 3, actor.RoundRobin())
+	require.NoError(t, err)
+
+	require.NoError(t, sys.Tell(pool, &actor.Resize{Size: 5}))
+	require.Len(t, routees(t, sys, pool), 5)
+	require.NoError(t, sys.Tell(pool, &actor.Resize{Size: 2}))
+	refs := routees(t, sys, pool)
+	require.Len(t, refs, 2)
+
+	// a crashed routee is replaced
+	require.NoError(t, sys.Kill(refs[0], false))
+	require.Eventually(t, func() bool {
+		current := routees(t, sys, pool)
+		return len(current) == 2 && current[0].String() == refs[1].String()
+	}, time.Second, time.Millisecond)
+
+	// the routees die with the pool
+	require.NoError(t, sys.Kill(pool, true))
+	require.Eventually(t, func() bool {
+		return sys.Tell(refs[1], ackMsg{}) != nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestGroupOverPool(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	pool, err := sys.SpawnPool(func() actor.Actor { return &routeeActor{} }, 2, actor.RoundRobin())
+	require.NoError(t, err)
+	group, err := sys.SpawnGroup([]actor.Ref{pool}, actor.RoundRobin())
+	require.NoError(t, err)
+
+	// the pool routes what the group routes to it
+	refs := routees(t, sys, pool)
+	for i := 0; i < 4; i++ {
+		require.Equal(t, refs[i%2].String(), askRoutee(t, sys, group))
+	}
+}
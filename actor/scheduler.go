@@ -0,0 +1,218 @@
+package actor
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cancellable is returned for scheduled messages
+type Cancellable interface {
+	// Cancel the schedule, returns false if it was already cancelled or done
+	Cancel() bool
+	Cancelled() bool
+}
+
+// Scheduler delivers messages after a delay
+type Scheduler interface {
+	// ScheduleOnce tells ref the message after delay
+	ScheduleOnce(delay time.Duration, ref Ref, msg Message, opts ...TalkOption) Cancellable
+	// ScheduleRepeatedly tells ref the message after initial and then every interval,
+	// until cancelled or ref is gone
+	ScheduleRepeatedly(initial, interval time.Duration, ref Ref, msg Message, opts ...TalkOption) Cancellable
+}
+
+// Timers schedule messages to the actor itself, they are cancelled when it stops
+// and starting a timer replaces the running one with the same key
+type Timers interface {
+	StartOnce(key any, delay time.Duration, msg Message)
+	StartRepeatedly(key any, initial, interval time.Duration, msg Message)
+	// Cancel the timer, its message is not handled even if it is already queued
+	Cancel(key any) bool
+	IsActive(key any) bool
+}
+
+var _ Cancellable = (*timer)(nil)
+
+type timer struct {
+	done      chan struct{}
+	over      atomic.Bool
+	cancelled atomic.Bool
+	// called once the timer is over
+	finish func()
+}
+
+func newTimer() *timer {
+	return &timer{done: make(chan struct{}), finish: func() {}}
+}
+
+func (t *timer) Cancel() bool {
+	if !t.over.CompareAndSwap(false, true) {
+		return false
+	}
+	t.cancelled.Store(true)
+	close(t.done)
+	return true
+}
+
+func (t *timer) Cancelled() bool {
+	return t.cancelled.Load()
+}
+
+// fromTimer marks envelopes sent by a timer, they are dropped once it is cancelled
+func fromTimer(t *timer) TalkOption {
+	return func(e *Envelope) {
+		e.timer = t
+	}
+}
+
+/* system scheduler */
+
+var _ Scheduler = (*system)(nil)
+
+// Scheduler of the system, schedules end when the system stops
+func (s *system) Scheduler() Scheduler {
+	return s
+}
+
+func (s *system) ScheduleOnce(delay time.Duration, ref Ref, msg Message, opts ...TalkOption) Cancellable {
+	t := newTimer()
+	go s.schedule(t, delay, 0, ref, msg, opts)
+	return t
+}
+
+func (s *system) ScheduleRepeatedly(initial, interval time.Duration, ref Ref, msg Message, opts ...TalkOption) Cancellable {
+	t := newTimer()
+	go s.schedule(t, initial, interval, ref, msg, opts)
+	return t
+}
+
+// schedule tells ref the message after initial, and every interval if it is not 0
+func (s *system) schedule(t *timer, initial, interval time.Duration, ref Ref, msg Message, opts []TalkOption) {
+	defer t.finish()
+	opts = append(opts, Tell, fromTimer(t))
+	wait := time.NewTimer(initial)
+	defer wait.Stop()
+	for {
+		select {
+		case <-wait.C:
+		case <-t.done:
+			return
+		case <-s.ctx.Done():
+			t.Cancel()
+			return
+		}
+		if interval <= 0 {
+			if t.over.CompareAndSwap(false, true) {
+				_ = s.send(ref, msg, opts...)
+			}
+			return
+		}
+		if t.over.Load() {
+			return
+		}
+		if err := s.send(ref, msg, opts...); err != nil && !errors.Is(err, ErrMailboxOverflow) {
+			s.log.Debug("stop repeating %T to %s: %s", msg, ref, err)
+			t.Cancel()
+			return
+		}
+		wait.Reset(interval)
+	}
+}
+
+/* actor scheduler */
+
+var (
+	_ Scheduler = (*actorScheduler)(nil)
+	_ Timers    = (*actorScheduler)(nil)
+)
+
+// actorScheduler tracks the timers of an actor to cancel them when it stops
+type actorScheduler struct {
+	actor *actor
+
+	lock   sync.Mutex
+	timers map[*timer]struct{}
+	keyed  map[any]*timer
+}
+
+func newActorScheduler(a *actor) *actorScheduler {
+	return &actorScheduler{
+		actor:  a,
+		timers: map[*timer]struct{}{},
+		keyed:  map[any]*timer{},
+	}
+}
+
+func (as *actorScheduler) ScheduleOnce(delay time.Duration, ref Ref, msg Message, opts ...TalkOption) Cancellable {
+	return as.start(nil, delay, 0, ref, msg, opts)
+}
+
+func (as *actorScheduler) ScheduleRepeatedly(initial, interval time.Duration, ref Ref, msg Message, opts ...TalkOption) Cancellable {
+	return as.start(nil, initial, interval, ref, msg, opts)
+}
+
+func (as *actorScheduler) StartOnce(key any, delay time.Duration, msg Message) {
+	as.start(key, delay, 0, &as.actor.ref, msg, nil)
+}
+
+func (as *actorScheduler) StartRepeatedly(key any, initial, interval time.Duration, msg Message) {
+	as.start(key, initial, interval, &as.actor.ref, msg, nil)
+}
+
+func (as *actorScheduler) Cancel(key any) bool {
+	as.lock.Lock()
+	t, ok := as.keyed[key]
+	as.lock.Unlock()
+	return ok && t.Cancel()
+}
+
+func (as *actorScheduler) IsActive(key any) bool {
+	as.lock.Lock()
+	defer as.lock.Unlock()
+	_, ok := as.keyed[key]
+	return ok
+}
+
+// start a timer tracked until it is over, replacing the one with the same key if key is not nil
+func (as *actorScheduler) start(key any, initial, interval time.Duration, ref Ref, msg Message, opts []TalkOption) *timer {
+	t := newTimer()
+	t.finish = func() {
+		as.lock.Lock()
+		defer as.lock.Unlock()
+		delete(as.timers, t)
+		if key != nil && as.keyed[key] == t {
+			delete(as.keyed, key)
+		}
+	}
+	as.lock.Lock()
+	if as.timers == nil {
+		// the actor stopped
+		as.lock.Unlock()
+		t.Cancel()
+		return t
+	}
+	as.timers[t] = struct{}{}
+	if key != nil {
+		if old, ok := as.keyed[key]; ok {
+			old.Cancel()
+		}
+		as.keyed[key] = t
+	}
+	as.lock.Unlock()
+	go as.actor.system.schedule(t, initial, interval, ref, msg, append(opts, internal))
+	return t
+}
+
+// cancelAll timers for good, called when the actor terminates
+func (as *actorScheduler) cancelAll() {
+	as.lock.Lock()
+	timers := as.timers
+	as.timers = nil
+	as.keyed = nil
+	as.lock.Unlock()
+	for t := range timers {
+		t.Cancel()
+	}
+}
@@ -0,0 +1,145 @@
+package actor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+func expectMsg(t *testing.T, msgs chan actor.Message, expected actor.Message) {
+	t.Helper()
+	select {
+	case msg := <-msgs:
+		require.Equal(t, expected, msg)
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for %v", expected)
+	}
+}
+
+func expectNoMsg(t *testing.T, msgs chan actor.Message, wait time.Duration) {
+	t.Helper()
+	select {
+	case msg := <-msgs:
+		t.Fatalf("unexpected %v", msg)
+	case <-time.After(wait):
+	}
+}
+
+func TestSchedulerOnce(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 10)}
+	ref, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+
+	start := time.Now()
+	c := sys.Scheduler().ScheduleOnce(10*time.Millisecond, ref, ackMsg{i: 1})
+	expectMsg(t, fwd.msgs, ackMsg{i: 1})
+	require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	require.False(t, c.Cancel())
+	require.False(t, c.Cancelled())
+
+	c = sys.Scheduler().ScheduleOnce(10*time.Millisecond, ref, ackMsg{i: 2})
+	require.True(t, c.Cancel())
+	require.True(t, c.Cancelled())
+	expectNoMsg(t, fwd.msgs, 30*time.Millisecond)
+}
+
+func TestSchedulerRepeatedly(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 100)}
+	ref, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+
+	c := sys.Scheduler().ScheduleRepeatedly(0, 5*time.Millisecond, ref, ackMsg{i: 1})
+	for i := 0; i < 3; i++ {
+		expectMsg(t, fwd.msgs, ackMsg{i: 1})
+	}
+	require.True(t, c.Cancel())
+	// drain what was sent before cancelling
+	time.Sleep(10 * time.Millisecond)
+	for len(fwd.msgs) > 0 {
+		<-fwd.msgs
+	}
+	expectNoMsg(t, fwd.msgs, 20*time.Millisecond)
+
+	// stops once the recipient is gone
+	c = sys.Scheduler().ScheduleRepeatedly(0, 5*time.Millisecond, ref, ackMsg{i: 2})
+	require.NoError(t, sys.Kill(ref, false))
+	require.Eventually(t, c.Cancelled, time.Second, time.Millisecond)
+}
+
+type tickMsg struct {
+	actor.Message
+	i int
+}
+
+// timerActor schedules ticks to target and keyed timers to itself
+type timerActor struct {
+	target actor.Ref
+}
+
+func (ta *timerActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case *actor.Start:
+		ctx.Scheduler().ScheduleRepeatedly(0, 5*time.Millisecond, ta.target, tickMsg{})
+	case ackMsg:
+		// the last one wins
+		ctx.Timers().StartOnce("retry", 50*time.Millisecond, tickMsg{i: msg.i})
+	case tickMsg:
+		return nil, ctx.Tell(ta.target, msg)
+	}
+	return nil, nil
+}
+
+func TestSchedulerActorTimers(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 100)}
+	fwdRef, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+	ref, err := sys.Spawn(&timerActor{target: fwdRef})
+	require.NoError(t, err)
+	expectMsg(t, fwd.msgs, tickMsg{})
+
+	// timers are cancelled when the actor stops, ticks sent before are dropped
+	w := &watchingActor{terminated: make(chan *actor.Terminated, 1)}
+	watcher, err := sys.Spawn(w)
+	require.NoError(t, err)
+	_, err = sys.Ask(watcher, watchMsg{ref: ref})
+	require.NoError(t, err)
+	require.NoError(t, sys.Kill(ref, true))
+	expectTerminated(t, w.terminated, ref)
+	require.Eventually(t, func() bool {
+		select {
+		case <-fwd.msgs:
+			return false
+		default:
+			return true
+		}
+	}, time.Second, 5*time.Millisecond)
+	require.Never(t, func() bool { return len(fwd.msgs) > 0 }, 50*time.Millisecond, time.Millisecond)
+
+	// keyed timers replace each other
+	ref, err = sys.Spawn(&timerActor{target: fwdRef})
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, sys.Tell(ref, ackMsg{i: i}))
+	}
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case msg := <-fwd.msgs:
+			if msg == (tickMsg{}) {
+				continue
+			}
+			require.Equal(t, tickMsg{i: 3}, msg)
+		case <-deadline:
+			t.Fatal("timeout")
+		}
+		break
+	}
+}
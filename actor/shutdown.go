@@ -0,0 +1,100 @@
+package actor
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// ShutdownError lists the actors that did not stop before the shutdown deadline
+type ShutdownError struct {
+	Refs []Ref
+	Err  error
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("%d actors did not stop in time %v: %s", len(e.Refs), e.Refs, e.Err)
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// Shutdown stops the system gracefully: new messages from outside the system
+// are rejected, the actors are stopped one by one, children before their parents
+// and the latest spawned first, each one handling the messages already queued.
+// Once ctx is done the remaining actors are killed and reported in a *ShutdownError.
+func (s *system) Shutdown(ctx context.Context) error {
+	s.log.Trace("Shutdown()")
+	s.closing.Store(true)
+	defer s.cancelCtx()
+
+	order := s.shutdownOrder()
+	for i, a := range order {
+		a.stop(true)
+		select {
+		case <-a.done:
+		case <-ctx.Done():
+			return s.abortShutdown(ctx, order[i:])
+		}
+	}
+
+	// wait for the loops to exit
+	exited := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+		return nil
+	case <-ctx.Done():
+		return s.abortShutdown(ctx, order)
+	}
+}
+
+// shutdownOrder returns all actors, children before their parents,
+// siblings and top level actors in reverse spawn order
+func (s *system) shutdownOrder() []*actor {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var roots []*actor
+	for _, a := range s.actors {
+		if a.parent == nil {
+			roots = append(roots, a)
+		}
+	}
+	slices.SortFunc(roots, func(a, b *actor) int {
+		return cmp.Compare(b.ref.id, a.ref.id)
+	})
+	order := make([]*actor, 0, len(s.actors))
+	var visit func(a *actor)
+	visit = func(a *actor) {
+		for i := len(a.children) - 1; i >= 0; i-- {
+			visit(a.children[i])
+		}
+		order = append(order, a)
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	return order
+}
+
+// abortShutdown kills the given actors and reports those not terminated yet
+func (s *system) abortShutdown(ctx context.Context, remaining []*actor) error {
+	var refs []Ref
+	for _, a := range remaining {
+		if a.terminated.Load() {
+			continue
+		}
+		refs = append(refs, &a.ref)
+		a.stop(false)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	s.log.Warn("shutdown: %d actors did not stop in time", len(refs))
+	return &ShutdownError{Refs: refs, Err: ctx.Err()}
+}
@@ -0,0 +1,108 @@
+package actor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+func TestShutdownDrains(t *testing.T) {
+	sys := newSystem()
+	a := &orderActor{order: make(chan int, 10)}
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
+	gate := make(chan struct{})
+	require.NoError(t, sys.Tell(ref, gateMsg{gate: gate}))
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 1}))
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 2}))
+	// stopped first, so its stop tells that the system is closing
+	events := make(chan string, 10)
+	_, err = sys.Spawn(&lifecycleActor{events: events})
+	require.NoError(t, err)
+	expectEvent(t, events, "start")
+
+	shutdown := make(chan error)
+	go func() { shutdown <- sys.Shutdown(context.Background()) }()
+	expectEvent(t, events, "stop")
+
+	// no new messages or actors once shutting down
+	require.ErrorIs(t, sys.Tell(ref, ackMsg{i: 3}), actor.ErrShuttingDown)
+	_, err = sys.Spawn(&ackActor{})
+	require.ErrorIs(t, err, actor.ErrShuttingDown)
+
+	close(gate)
+	select {
+	case err := <-shutdown:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("shutdown blocked")
+	}
+	order := []int{}
+	for i := range a.order {
+		order = append(order, i)
+	}
+	require.Equal(t, []int{1, 2}, order)
+}
+
+// stopOrderActor reports its id when stopped and spawns children
+type stopOrderActor struct {
+	id    int
+	stops chan int
+}
+
+func (sa *stopOrderActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case spawnChild:
+		ref, err := ctx.Spawn(&stopOrderActor{id: msg.id, stops: sa.stops})
+		if err != nil {
+			return nil, err
+		}
+		return childRef{Ref: ref}, nil
+	case *actor.Stop:
+		sa.stops <- sa.id
+	}
+	return nil, nil
+}
+
+func TestShutdownOrder(t *testing.T) {
+	sys := newSystem()
+	stops := make(chan int, 10)
+	first, err := sys.Spawn(&stopOrderActor{id: 1, stops: stops})
+	require.NoError(t, err)
+	for _, id := range []int{11, 12} {
+		_, err = sys.Ask(first, spawnChild{id: id})
+		require.NoError(t, err)
+	}
+	_, err = sys.Spawn(&stopOrderActor{id: 2, stops: stops})
+	require.NoError(t, err)
+
+	require.NoError(t, sys.Shutdown(context.Background()))
+	close(stops)
+	order := []int{}
+	for id := range stops {
+		order = append(order, id)
+	}
+	require.Equal(t, []int{2, 12, 11, 1}, order)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	sys := newSystem()
+	a := &orderActor{order: make(chan int, 10)}
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
+	gate := make(chan struct{})
+	defer close(gate)
+	require.NoError(t, sys.Tell(ref, gateMsg{gate: gate}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = sys.Shutdown(ctx)
+	var shutdownErr *actor.ShutdownError
+	require.ErrorAs(t, err, &shutdownErr)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Len(t, shutdownErr.Refs, 1)
+	require.Equal(t, ref.String(), shutdownErr.Refs[0].String())
+}
@@ -0,0 +1,37 @@
+package actor
+
+// the amount of envelopes an actor can stash by default
+const DefaultStashSize = 1000
+
+// WithStashSize sets the amount of envelopes the actor can stash,
+// envelopes stashed beyond go to the dead letters
+func WithStashSize(size int) SpawnOption {
+	return func(a *actor) {
+		a.stashSize = size
+	}
+}
+
+// stash sets the envelope aside, called from within the loop only
+func (a *actor) stash(envelope *Envelope) error {
+	if len(a.stashed) >= a.stashSize {
+		err := ErrStashFull(&a.ref)
+		a.system.deadLetter(&a.ref, envelope, err)
+		return err
+	}
+	a.stashed = append(a.stashed, envelope)
+	return nil
+}
+
+// unstashAll puts the stashed envelopes in front of the mailbox, called from within the loop only
+func (a *actor) unstashAll() {
+	if len(a.stashed) == 0 {
+		return
+	}
+	a.mailbox.unstashed = append(a.stashed, a.mailbox.unstashed...)
+	a.stashed = nil
+}
+
+// isStashed tells whether the envelope was just stashed, it is replied to once unstashed
+func (a *actor) isStashed(envelope *Envelope) bool {
+	return len(a.stashed) > 0 && a.stashed[len(a.stashed)-1] == envelope
+}
@@ -0,0 +1,137 @@
+package actor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+type readyMsg struct {
+	actor.Message
+}
+
+// stashingActor stashes ackMsgs until it is ready, reports
+// trying to stash Start if startErrs is set
+type stashingActor struct {
+	ready     bool
+	order     chan int
+	errs      chan error
+	startErrs chan error
+}
+
+func (sa *stashingActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case *actor.Start:
+		if sa.startErrs != nil {
+			sa.startErrs <- ctx.Stash()
+		}
+	case failMsg:
+		return nil, actor.Fatal(errors.New("boom"))
+	case readyMsg:
+		sa.ready = true
+		ctx.UnstashAll()
+	case ackMsg:
+		if !sa.ready {
+			if err := ctx.Stash(); err != nil {
+				sa.errs <- err
+			}
+			return nil, nil
+		}
+		sa.order <- msg.i
+		return msg, nil
+	}
+	return nil, nil
+}
+
+func TestStash(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	a := &stashingActor{order: make(chan int, 10), errs: make(chan error, 10)}
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
+
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 1}))
+	reply := sys.AskAsync(ref, ackMsg{i: 2})
+	require.NoError(t, sys.Tell(ref, readyMsg{}))
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 3}))
+
+	// stashed envelopes come first in their original order
+	for _, i := range []int{1, 2, 3} {
+		select {
+		case got := <-a.order:
+			require.Equal(t, i, got)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	// the sender is kept, the asker gets its reply once unstashed
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, err := reply.Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ackMsg{i: 2}, msg)
+}
+
+func TestStashOverflow(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	fwd := &forwardActor{msgs: make(chan actor.Message, 10)}
+	fwdRef, err := sys.Spawn(fwd)
+	require.NoError(t, err)
+	sys.DeadLetters().Subscribe(fwdRef)
+
+	a := &stashingActor{order: make(chan int, 10), errs: make(chan error, 10)}
+	ref, err := sys.Spawn(a, actor.WithStashSize(2))
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, sys.Tell(ref, ackMsg{i: i}))
+	}
+	select {
+	case err := <-a.errs:
+		require.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("no stash error")
+	}
+	dl := expectDeadLetter(t, fwd.msgs)
+	require.Equal(t, ackMsg{i: 3}, dl.Msg)
+
+	require.NoError(t, sys.Tell(ref, readyMsg{}))
+	for _, i := range []int{1, 2} {
+		select {
+		case got := <-a.order:
+			require.Equal(t, i, got)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func TestStashStart(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	a := &stashingActor{order: make(chan int, 10), errs: make(chan error, 10), startErrs: make(chan error, 10)}
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
+	require.ErrorIs(t, <-a.startErrs, actor.ErrNothingToStash)
+
+	// the failed message is not the one handled while restarting
+	require.NoError(t, sys.Tell(ref, failMsg{}))
+	select {
+	case err := <-a.startErrs:
+		require.ErrorIs(t, err, actor.ErrNothingToStash)
+	case <-time.After(time.Second):
+		t.Fatal("not restarted")
+	}
+	require.NoError(t, sys.Tell(ref, readyMsg{}))
+	require.NoError(t, sys.Tell(ref, ackMsg{i: 1}))
+	select {
+	case got := <-a.order:
+		require.Equal(t, 1, got)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
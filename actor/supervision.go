@@ -0,0 +1,215 @@
+package actor
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+const (
+	// the default amount of restarts allowed within DefaultRestartWindow
+	DefaultMaxRestarts = 10
+
+	// the default window restarts are counted in
+	DefaultRestartWindow = 1 * time.Minute
+)
+
+// StrategyKind defines which children are restarted when one of them fails
+type StrategyKind int
+
+const (
+	// only the failed child is restarted
+	OneForOne StrategyKind = iota
+	// all children are restarted
+	OneForAll
+	// the failed child and all children spawned after it are restarted
+	RestForOne
+)
+
+func (k StrategyKind) String() string {
+	switch k {
+	case OneForOne:
+		return "one-for-one"
+	case OneForAll:
+		return "one-for-all"
+	case RestForOne:
+		return "rest-for-one"
+	}
+	return fmt.Sprintf("strategy#%d", int(k))
+}
+
+// SupervisorStrategy decides how a parent handles failures of its children.
+// When a child fails more than MaxRestarts times within Within, the affected
+// children are stopped instead of restarted.
+type SupervisorStrategy struct {
+	Kind        StrategyKind
+	MaxRestarts int
+	Within      time.Duration
+}
+
+// DefaultSupervisorStrategy is used for top level actors and for parents
+// that have no strategy set via WithSupervisor
+var DefaultSupervisorStrategy = SupervisorStrategy{
+	Kind:        OneForOne,
+	MaxRestarts: DefaultMaxRestarts,
+	Within:      DefaultRestartWindow,
+}
+
+// affected returns the children that are restarted or stopped when failed fails
+func (st SupervisorStrategy) affected(failed *actor, children []*actor) []*actor {
+	switch st.Kind {
+	case OneForAll:
+		return slices.Clone(children)
+	case RestForOne:
+		if idx := slices.Index(children, failed); idx >= 0 {
+			return slices.Clone(children[idx:])
+		}
+	}
+	return []*actor{failed}
+}
+
+// Failure is returned from Actor.Handle to signal that the actor is broken
+// and has to be handled by its supervisor
+type Failure struct {
+	Reason error
+}
+
+// Fatal wraps err so that the actor returning it is reported to its supervisor
+func Fatal(err error) error {
+	return &Failure{Reason: err}
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("actor failed: %s", f.Reason)
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Reason
+}
+
+// PanicError is the failure reason of an actor that panicked while handling a message
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// ErrorSink receives failures of actors
+type ErrorSink func(ref Ref, err error)
+
+// RestartPolicy defines how long a failed actor waits before it is restarted.
+// The backoff doubles with each restart within the supervisor's window, starting
+// at MinBackoff up to MaxBackoff, and is extended by a random share of up to Jitter.
+type RestartPolicy struct {
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	Jitter     float64
+}
+
+// backoff returns the delay before the n-th restart
+func (p RestartPolicy) backoff(n int) time.Duration {
+	if p.MinBackoff <= 0 || n <= 0 {
+		return 0
+	}
+	d := p.MinBackoff
+	for i := 1; i < n && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d += time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// restart is sent to actors restarted due to a sibling's failure
+type restart struct {
+	Message
+	reason error
+}
+
+func (*restart) systemMessage() {}
+
+// allowRestart records a restart and reports whether it is within the
+// limits of the strategy, system lock must be held
+func (a *actor) allowRestart(st SupervisorStrategy, now time.Time) bool {
+	if st.Within > 0 {
+		a.restarts = slices.DeleteFunc(a.restarts, func(t time.Time) bool {
+			return now.Sub(t) > st.Within
+		})
+	}
+	if len(a.restarts) >= st.MaxRestarts {
+		return false
+	}
+	a.restarts = append(a.restarts, now)
+	return true
+}
+
+// supervise reports the failure to the error sink, applies the strategy of the
+// failed actor's parent to the affected actors and reports whether the failed
+// actor should restart and after which delay
+func (s *system) supervise(failed *actor, reason error) (bool, time.Duration) {
+	s.lock.Lock()
+	sink := s.errorSink
+	if _, ok := s.actors[failed.ref]; !ok {
+		// already killed
+		s.lock.Unlock()
+		sink(&failed.ref, reason)
+		return false, 0
+	}
+	strategy := DefaultSupervisorStrategy
+	children := []*actor{failed}
+	if failed.parent != nil {
+		strategy = failed.parent.strategy
+		children = failed.parent.children
+	}
+	affected := strategy.affected(failed, children)
+	allowed := failed.allowRestart(strategy, time.Now())
+	backoff := failed.restartPolicy.backoff(len(failed.restarts))
+	s.lock.Unlock()
+
+	sink(&failed.ref, reason)
+	if !allowed {
+		s.log.Error("actor %s exceeded %d restarts within %s, stopping (%s)", &failed.ref, strategy.MaxRestarts, strategy.Within, strategy.Kind)
+		for _, a := range affected {
+			_ = s.Kill(&a.ref, false)
+		}
+		return false, 0
+	}
+	s.log.Warn("restarting actor %s due to: %s (%s)", &failed.ref, reason, strategy.Kind)
+	for _, a := range affected {
+		if a != failed {
+			_ = s.send(&a.ref, &restart{reason: reason})
+		}
+	}
+	return true, backoff
+}
+
+// WithSupervisor sets the strategy the actor applies to its children
+func WithSupervisor(kind StrategyKind, maxRestarts int, within time.Duration) SpawnOption {
+	return func(a *actor) {
+		a.strategy = SupervisorStrategy{Kind: kind, MaxRestarts: maxRestarts, Within: within}
+	}
+}
+
+// WithFactory sets a function that creates a fresh actor instance on restarts,
+// without it the instance is reused, it creates the first one as well if the
+// spawned instance is nil
+func WithFactory(factory func() Actor) SpawnOption {
+	return func(a *actor) {
+		a.factory = factory
+	}
+}
+
+// WithRestartPolicy sets the backoff applied before the actor is restarted
+func WithRestartPolicy(policy RestartPolicy) SpawnOption {
+	return func(a *actor) {
+		a.restartPolicy = policy
+	}
+}
@@ -0,0 +1,196 @@
+package actor_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+type failMsg struct {
+	actor.Message
+}
+
+type spawnChild struct {
+	actor.Message
+	id int
+}
+
+type childRef struct {
+	actor.Message
+	actor.Ref
+}
+
+// supervisedActor reports its starts and fails on failMsg
+type supervisedActor struct {
+	id     int
+	starts chan int
+}
+
+func (sa *supervisedActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg.(type) {
+	case *actor.Start:
+		sa.starts <- sa.id
+	case failMsg:
+		return nil, actor.Fatal(errors.New("boom"))
+	}
+	return nil, nil
+}
+
+// supervisorActor spawns supervisedActors as children
+type supervisorActor struct {
+	starts chan int
+}
+
+func (sa *supervisorActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case spawnChild:
+		ref, err := ctx.Spawn(&supervisedActor{id: msg.id, starts: sa.starts})
+		if err != nil {
+			return nil, err
+		}
+		return childRef{Ref: ref}, nil
+	}
+	return nil, nil
+}
+
+func expectStarts(t *testing.T, starts chan int, ids ...int) {
+	t.Helper()
+	got := []int{}
+	for range ids {
+		select {
+		case id := <-starts:
+			got = append(got, id)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout, got starts %v, expected %v", got, ids)
+		}
+	}
+	require.ElementsMatch(t, ids, got)
+	select {
+	case id := <-starts:
+		t.Fatalf("unexpected start of %d", id)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func spawnSupervised(t *testing.T, sys actor.System, starts chan int, opts ...actor.SpawnOption) (parent actor.Ref, children []actor.Ref) {
+	t.Helper()
+	parent, err := sys.Spawn(&supervisorActor{starts: starts}, opts...)
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		reply, err := sys.Ask(parent, spawnChild{id: i})
+		require.NoError(t, err)
+		children = append(children, reply.(childRef).Ref)
+	}
+	expectStarts(t, starts, 1, 2, 3)
+	return
+}
+
+func TestSupervisionStrategies(t *testing.T) {
+	for _, tc := range []struct {
+		kind      actor.StrategyKind
+		restarted []int
+	}{
+		{actor.OneForOne, []int{2}},
+		{actor.OneForAll, []int{1, 2, 3}},
+		{actor.RestForOne, []int{2, 3}},
+	} {
+		t.Run(tc.kind.String(), func(t *testing.T) {
+			sys := newSystem()
+			defer sys.Stop()
+			starts := make(chan int, 10)
+			_, children := spawnSupervised(t, sys, starts, actor.WithSupervisor(tc.kind, 1, time.Minute))
+
+			require.NoError(t, sys.Tell(children[1], failMsg{}))
+			expectStarts(t, starts, tc.restarted...)
+
+			// second failure exceeds the limit and stops the affected children
+			reply, err := sys.Ask(children[1], failMsg{})
+			require.NoError(t, err)
+			require.IsType(t, &actor.Error{}, reply)
+			for i, child := range children {
+				if slices.Contains(tc.restarted, i+1) {
+					require.Eventually(t, func() bool {
+						return sys.Tell(child, nil) != nil
+					}, time.Second, time.Millisecond)
+				} else {
+					require.NoError(t, sys.Tell(child, nil))
+				}
+			}
+		})
+	}
+}
+
+func TestSupervisionChildrenDieWithParent(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	starts := make(chan int, 10)
+	parent, children := spawnSupervised(t, sys, starts)
+
+	require.NoError(t, sys.Kill(parent, true))
+	for _, child := range children {
+		require.Error(t, sys.Tell(child, nil))
+	}
+}
+
+type panicMsg struct {
+	actor.Message
+}
+
+// panicActor panics on panicMsg and counts the other messages
+type panicActor struct {
+	cnt int
+}
+
+func (pa *panicActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg.(type) {
+	case panicMsg:
+		panic("oh no")
+	case ackMsg:
+		pa.cnt++
+		return ackMsg{i: pa.cnt}, nil
+	}
+	return nil, nil
+}
+
+func TestSupervisionPanicRecovery(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	failures := make(chan error, 1)
+	sys.SetErrorSink(func(ref actor.Ref, err error) {
+		failures <- err
+	})
+	backoff := 50 * time.Millisecond
+	ref, err := sys.Spawn(&panicActor{}, actor.WithFactory(func() actor.Actor { return &panicActor{} }),
+		actor.WithRestartPolicy(actor.RestartPolicy{MinBackoff: backoff, MaxBackoff: time.Second, Jitter: 0.1}))
+	require.NoError(t, err)
+
+	reply, err := sys.Ask(ref, ackMsg{})
+	require.NoError(t, err)
+	require.Equal(t, 1, reply.(ackMsg).i)
+
+	start := time.Now()
+	reply, err = sys.Ask(ref, panicMsg{})
+	require.NoError(t, err)
+	require.IsType(t, &actor.Error{}, reply)
+	var p *actor.PanicError
+	require.ErrorAs(t, reply.(*actor.Error).Error, &p)
+	require.Equal(t, "oh no", p.Value)
+
+	select {
+	case err := <-failures:
+		require.ErrorAs(t, err, &p)
+		require.NotEmpty(t, p.Stack)
+	case <-time.After(time.Second):
+		t.Fatal("failure not reported")
+	}
+
+	// same ref, fresh instance after the backoff
+	reply, err = sys.Ask(ref, ackMsg{})
+	require.NoError(t, err)
+	require.Equal(t, 1, reply.(ackMsg).i)
+	require.GreaterOrEqual(t, time.Since(start), backoff)
+}
@@ -2,8 +2,12 @@ package actor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/thlcodes/go-actress/log"
@@ -12,8 +16,19 @@ import (
 type System interface {
 	supervisor
 	talker
+	namedTalker
+	Lookup(name string) (Ref, bool)
+	TellType(typ reflect.Type, what Message, opts ...TalkOption) error
 	Stop()
+	Shutdown(ctx context.Context) error
 	SetLogger(log.Logger)
+	SetErrorSink(ErrorSink)
+	DeadLetters() DeadLetterOffice
+	Scheduler() Scheduler
+	EventStream() EventStream
+	UseMiddleware(middleware ...Middleware)
+	UseSendMiddleware(middleware ...SendMiddleware)
+	Metrics() Metrics
 }
 
 var _ System = (*system)(nil)
@@ -22,24 +37,49 @@ type system struct {
 	ctx       context.Context
 	cancelCtx func()
 
-	log log.Logger
+	log         log.Logger
+	errorSink   ErrorSink
+	deadLetters *deadLetterOffice
+	events      *eventStream
+	middlewares middlewares
 
-	lock    sync.RWMutex
-	currIdx uint64
+	lock      sync.RWMutex
+	currIdx   uint64
+	futureIdx atomic.Uint64
+
+	// shutdown
+	closing atomic.Bool
+	loops   sync.WaitGroup
 
 	actors map[localRef]*actor
+	names  map[string]localRef
+	types  map[reflect.Type]map[localRef]struct{}
+
+	// metrics of the stopped actors per type
+	typeStats map[reflect.Type]*stats
+	// unregistered actors until they terminated, they can still be watched
+	// and their metrics still count
+	stopping map[localRef]*actor
 }
 
 // NewSystem will create a new actor system
 func NewSystem(ctx context.Context) System {
 	ctx, cancel := context.WithCancel(ctx)
-	return &system{
+	s := &system{
 		ctx:       ctx,
 		log:       log.NewStdLogger().WithLevel(log.INFO).WithPrefix("System"),
 		cancelCtx: cancel,
 		currIdx:   0,
 		actors:    map[localRef]*actor{},
+		names:     map[string]localRef{},
+		types:     map[reflect.Type]map[localRef]struct{}{},
+		typeStats: map[reflect.Type]*stats{},
+		stopping:  map[localRef]*actor{},
 	}
+	s.errorSink = s.logFailure
+	s.events = newEventStream(ctx, s)
+	s.deadLetters = newDeadLetterOffice(ctx, s)
+	return s
 }
 
 // Set logger
@@ -47,22 +87,116 @@ func (s *system) SetLogger(log log.Logger) {
 	s.log = log
 }
 
+// SetErrorSink sets the function actor failures are reported to,
+// by default failures are logged
+func (s *system) SetErrorSink(sink ErrorSink) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if sink == nil {
+		sink = s.logFailure
+	}
+	s.errorSink = sink
+}
+
+// reportFailure passes the failure to the error sink
+func (s *system) reportFailure(ref Ref, err error) {
+	s.lock.RLock()
+	sink := s.errorSink
+	s.lock.RUnlock()
+	sink(ref, err)
+}
+
+func (s *system) logFailure(ref Ref, err error) {
+	var p *PanicError
+	if errors.As(err, &p) {
+		s.log.Error("actor %s panicked: %v\n%s", ref, p.Value, p.Stack)
+		return
+	}
+	s.log.Error("actor %s failed: %s", ref, err)
+}
+
 // Spawn will start given actor instance
-func (s *system) Spawn(instance Actor, opts ...SpawnOption) Ref {
-	s.log.Trace("Spawn(instance=%T)", instance)
+func (s *system) Spawn(instance Actor, opts ...SpawnOption) (Ref, error) {
+	return s.spawn(instance, nil, opts...)
+}
+
+// spawn starts the given actor instance as child of parent,
+// or as top level actor if parent is nil
+func (s *system) spawn(instance Actor, parent Ref, opts ...SpawnOption) (Ref, error) {
+	s.log.Trace("spawn(instance=%T,parent=%v)", instance, parent)
+	if parent == nil && s.closing.Load() {
+		return nil, ErrShuttingDown
+	}
+	s.lock.Lock()
+	var parentActor *actor
+	if parent != nil {
+		lref, ok := parent.(*localRef)
+		if !ok {
+			s.lock.Unlock()
+			return nil, ErrUnsupportedRef(parent)
+		}
+		if parentActor, ok = s.actors[*lref]; !ok {
+			s.lock.Unlock()
+			return nil, ErrActorNotFound(parent)
+		}
+	}
 	s.currIdx++
 	ref := newLocalRef(s.currIdx)
-	actor := newActor(instance, DefaultMailboxSize, s.log.SubLogger(fmt.Sprintf("actor#%d", ref.id)))
-	actor.start(newActorContext(s.ctx, s, &ref))
+	s.lock.Unlock()
+	actor := newActor(instance, DefaultMailboxFactory, s.log.SubLogger(fmt.Sprintf("actor#%d", ref.id)))
+	actor.system = s
+	actor.ref = ref
+	actor.middleware = slices.Clone(s.handleMiddleware())
+	for _, opt := range opts {
+		opt(actor)
+	}
+	if actor.impl == nil {
+		if actor.factory == nil {
+			return nil, ErrActorNotImplemented
+		}
+		// the first instance is created by the factory as well
+		if actor.impl = actor.factory(); actor.impl == nil {
+			return nil, ErrActorNotImplemented
+		}
+		actor.typ = reflect.TypeOf(actor.impl)
+	}
+	actor.handler = chain(actor.dispatch, actor.middleware...)
+	actor.stats = &stats{}
 	s.lock.Lock()
+	if parentActor != nil && s.actors[parentActor.ref] != parentActor {
+		s.lock.Unlock()
+		return nil, ErrActorNotFound(parent)
+	}
+	if actor.name != "" {
+		if _, taken := s.names[actor.name]; taken {
+			s.lock.Unlock()
+			return nil, ErrNameTaken(actor.name)
+		}
+		s.names[actor.name] = ref
+	}
 	s.actors[ref] = actor
+	if s.types[actor.typ] == nil {
+		s.types[actor.typ] = map[localRef]struct{}{}
+	}
+	s.types[actor.typ][ref] = struct{}{}
+	if parentActor != nil {
+		actor.parent = parentActor
+		parentActor.children = append(parentActor.children, actor)
+	}
+	actor.ctx = newActorContext(s.ctx, actor)
+	started := make(chan error, 1)
+	actor.started = started
 	s.lock.Unlock()
+	actor.start()
+	if err := <-started; err != nil {
+		return nil, ErrPreStartFailed(&ref, err)
+	}
 	s.log.Debug("Spawned new local actor with ref %#v", ref)
-	_ = s.Tell(&ref, &Start{})
-	return &ref
+	s.events.Publish(&ActorSpawned{Ref: &actor.ref, Parent: parent})
+	return &ref, nil
 }
 
-// Kill an actor, optinally graceful
+// Kill an actor and all its children, optinally graceful
 func (s *system) Kill(ref Ref, graceful bool) error {
 	s.log.Trace("Kill(ref=%#v,graceful=%t)", ref, graceful)
 	lref, ok := ref.(*localRef)
@@ -70,13 +204,74 @@ func (s *system) Kill(ref Ref, graceful bool) error {
 		return ErrUnsupportedRef(ref)
 	}
 	s.lock.Lock()
-	actor := s.actors[*lref]
-	delete(s.actors, *lref)
+	actor, ok := s.actors[*lref]
+	if !ok {
+		s.lock.Unlock()
+		return ErrActorNotFound(ref)
+	}
+	stopped := s.unregister(actor)
 	s.lock.Unlock()
-	actor.stop(graceful)
+	for _, a := range stopped {
+		a.stop(graceful)
+	}
 	return nil
 }
 
+// unregister removes the actor and its children from all indexes and
+// returns them children first, lock must be held
+func (s *system) unregister(a *actor) (unregistered []*actor) {
+	children := slices.Clone(a.children)
+	for i := len(children) - 1; i >= 0; i-- {
+		unregistered = append(unregistered, s.unregister(children[i])...)
+	}
+	delete(s.actors, a.ref)
+	s.stopping[a.ref] = a
+	if a.name != "" {
+		delete(s.names, a.name)
+	}
+	delete(s.types[a.typ], a.ref)
+	if len(s.types[a.typ]) == 0 {
+		delete(s.types, a.typ)
+	}
+	if a.parent != nil {
+		a.parent.children = slices.DeleteFunc(a.parent.children, func(c *actor) bool { return c == a })
+	}
+	return append(unregistered, a)
+}
+
+// local returns the registered actor of ref
+func (s *system) local(ref Ref) (*actor, bool) {
+	lref, ok := ref.(*localRef)
+	if !ok {
+		return nil, false
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	a, ok := s.actors[*lref]
+	return a, ok
+}
+
+// Lookup returns the ref of the actor registered under the given name
+func (s *system) Lookup(name string) (Ref, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	ref, ok := s.names[name]
+	if !ok {
+		return nil, false
+	}
+	return &ref, true
+}
+
+// resolve looks up a named actor and returns an error if there is none
+func (s *system) resolve(name string) (Ref, error) {
+	ref, ok := s.Lookup(name)
+	if !ok {
+		return nil, ErrNameNotFound(name)
+	}
+	return ref, nil
+}
+
+// Stop cancels all actors right away, use Shutdown to let them finish their work
 func (s *system) Stop() {
 	s.log.Trace("Stop()")
 	// propagate cancel via context
@@ -91,70 +286,164 @@ func (s *system) Tell(whom Ref, what Message, opts ...TalkOption) error {
 }
 
 func (s *system) send(whom Ref, what Message, opts ...TalkOption) error {
-	dropWhenFull := false
-	var ch chan<- *Envelope
+	return s.sendEnvelope(whom, NewEnvelope(what, opts...))
+}
+
+// transmit the envelope to whom
+func (s *system) transmit(whom Ref, envelope *Envelope) error {
 	switch ref := whom.(type) {
-	case *channelRef:
-		ch = ref.ch
+	case *futureRef:
+		if envelope.correlationID == 0 || envelope.correlationID != ref.requestID {
+			s.deadLetter(whom, envelope, ErrUncorrelatedReply)
+			return nil
+		}
+		if !ref.future.complete(envelope.msg, nil) {
+			s.deadLetter(whom, envelope, ErrLateReply)
+		}
+		return nil
 	case *localRef:
+		if _, ok := envelope.msg.(systemMessage); !ok && !envelope.internal && s.closing.Load() {
+			s.deadLetter(whom, envelope, ErrShuttingDown)
+			return ErrShuttingDown
+		}
 		s.lock.RLock()
 		actor, ok := s.actors[*ref]
-		dropWhenFull = actor.dropWhenFull
 		s.lock.RUnlock()
 		if !ok {
-			return ErrActorNotFound(ref)
+			err := ErrActorNotFound(ref)
+			s.deadLetter(whom, envelope, err)
+			return err
 		}
-		ch = actor.channel()
+		if actor.router != nil {
+			switch envelope.msg.(type) {
+			case systemMessage, routerMessage:
+			default:
+				return actor.router.route(s, whom, envelope)
+			}
+		}
+		err := actor.deliver(envelope)
+		if err != nil {
+			s.deadLetter(whom, envelope, err)
+		}
+		return err
 	default:
 		return ErrUnsupportedRefForTalking(ref)
 	}
+}
 
-	envelope := NewEnvelope(what, opts...)
-	if dropWhenFull {
-		select {
-		case ch <- envelope:
-		// yeah!
-		default:
-			// mailbox/channel full
-			s.log.Warn("%s's mailbox full", whom)
-			return ErrMailboxFull(whom)
+// TellName sends a message to the actor with the given name but not wait for a reply
+func (s *system) TellName(name string, what Message, opts ...TalkOption) error {
+	ref, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	return s.Tell(ref, what, opts...)
+}
+
+// TellType sends a message to all live actors whose implementation is of the given type
+func (s *system) TellType(typ reflect.Type, what Message, opts ...TalkOption) error {
+	s.log.Trace("TellType(typ=%s,what=%T)", typ, what)
+	s.lock.RLock()
+	refs := make([]localRef, 0, len(s.types[typ]))
+	for ref := range s.types[typ] {
+		refs = append(refs, ref)
+	}
+	s.lock.RUnlock()
+	var errs []error
+	for _, ref := range refs {
+		if err := s.Tell(&ref, what, opts...); err != nil {
+			errs = append(errs, err)
 		}
-	} else {
-		ch <- envelope
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
-// TODO: as option
+// AskTimeout is the default timeout of Ask, override it per call with WithTimeout
 const AskTimeout = 3 * time.Second
 
 // Ask will send a message to an actor ref, intercept the response/error and return
-// it to the sender
+// it to the sender, waiting at most AskTimeout unless set otherwise via WithTimeout
 func (s *system) Ask(whom Ref, what Message, opts ...TalkOption) (reply Message, err error) {
-	s.log.Trace("Ask(whom=%s,what=%T,opts=%T)", whom, what, opts)
-	ch := make(chan *Envelope, 1)
-	cref := newChannelRef(ch)
-	if err = s.send(whom, what, WithSender(&cref)); err != nil {
-		return
+	return s.AskContext(s.ctx, whom, what, append([]TalkOption{WithTimeout(AskTimeout)}, opts...)...)
+}
+
+// AskContext will send a message to an actor ref and wait for the response/error until
+// the context is done or the timeout set via WithTimeout expired, the deadline is passed
+// on to the receiver
+func (s *system) AskContext(ctx context.Context, whom Ref, what Message, opts ...TalkOption) (reply Message, err error) {
+	s.log.Trace("AskContext(whom=%s,what=%T,opts=%T)", whom, what, opts)
+	return s.askAsync(ctx, whom, what, opts...).Await(context.Background())
+}
+
+// AskAsync will send a message to an actor ref and return a future of the response/error
+// without blocking, the future fails after AskTimeout unless set otherwise via WithTimeout
+func (s *system) AskAsync(whom Ref, what Message, opts ...TalkOption) *Future {
+	s.log.Trace("AskAsync(whom=%s,what=%T,opts=%T)", whom, what, opts)
+	return s.askAsync(s.ctx, whom, what, append([]TalkOption{WithTimeout(AskTimeout)}, opts...)...)
+}
+
+// askAsync sends the message with a future ref as sender, the future fails when ctx is done
+func (s *system) askAsync(ctx context.Context, whom Ref, what Message, opts ...TalkOption) *Future {
+	future := newFuture(s)
+	fref := newFutureRef(s.futureIdx.Add(1), future)
+	envelope := NewEnvelope(what, append(opts, WithSender(&fref))...)
+	fref.requestID = envelope.id
+	cancel := func() {}
+	if envelope.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, envelope.timeout)
 	}
-	var replyEnvelope *Envelope
-	var ok bool
-	select {
-	case replyEnvelope, ok = <-cref.ch:
-		if !ok {
-			return nil, ErrChannelRefChannelClosed(&cref)
-		}
-	case <-time.After(AskTimeout):
-		return nil, ErrTalkTimeout
+	envelope.deadline, _ = ctx.Deadline()
+	stop := context.AfterFunc(ctx, func() {
+		future.complete(nil, fmt.Errorf("%w: %w", ErrTalkTimeout, ctx.Err()))
+	})
+	future.OnComplete(func(Message, error) {
+		stop()
+		cancel()
+	})
+	if err := s.sendEnvelope(whom, envelope); err != nil {
+		future.complete(nil, err)
 	}
-	return replyEnvelope.msg, nil
+	return future
+}
+
+// AskName sends a message to the actor with the given name and waits for the reply
+func (s *system) AskName(name string, what Message, opts ...TalkOption) (reply Message, err error) {
+	ref, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Ask(ref, what, opts...)
 }
 
 // SpawnOptions
 
+// WithMailbox sets a bounded mailbox of the given size, that either blocks
+// or drops new messages while it is full. A size of 0 is raised to 1, the
+// mailbox is not a rendezvous anymore like the unbuffered channel it used to be
 func WithMailbox(size uint32, dropping bool) SpawnOption {
+	if dropping {
+		return WithMailboxFactory(func() Mailbox { return NewDropNewestMailbox(int(size)) })
+	}
+	return WithMailboxFactory(func() Mailbox { return NewBlockingMailbox(int(size)) })
+}
+
+// WithPassivation stops the actor after it was idle for the given timeout,
+// the next message reactivates it with a fresh instance from the factory set
+// via WithFactory or with the same instance if there is none
+func WithPassivation(timeout time.Duration) SpawnOption {
+	return func(a *actor) {
+		a.passivation = timeout
+	}
+}
+
+// WithName registers the actor under a system wide unique name
+func WithName(name string) SpawnOption {
 	return func(a *actor) {
-		a.mailbox = make(chan *Envelope, size)
-		a.dropWhenFull = dropping
+		a.name = name
 	}
 }
+
+// TellType sends a message to all live actors implemented by T
+func TellType[T Actor](sys System, what Message, opts ...TalkOption) error {
+	return sys.TellType(reflect.TypeFor[T](), what, opts...)
+}
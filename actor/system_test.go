@@ -3,6 +3,7 @@ package actor_test
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -27,7 +28,8 @@ func TestSystemDoesNotPanic(t *testing.T) {
 func TestSystemSpawn(t *testing.T) {
 	sys := newSystem()
 	a := &simpleActor{}
-	ref := sys.Spawn(a)
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
 	require.NotNil(t, ref)
 	sys.Stop()
 	time.Sleep(time.Millisecond)
@@ -68,7 +70,8 @@ func TestSystemTell(t *testing.T) {
 	a := &ackActor{
 		ack: make(chan ackMsg, 10),
 	}
-	ref := sys.Spawn(a)
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
 	require.NotNil(t, ref)
 
 	n := 5
@@ -99,7 +102,8 @@ func TestSystemAsk(t *testing.T) {
 	sys := newSystem()
 	defer sys.Stop()
 	a := &ackActor{}
-	ref := sys.Spawn(a)
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
 	require.NotNil(t, ref)
 
 	n := 5
@@ -111,6 +115,311 @@ func TestSystemAsk(t *testing.T) {
 	}
 }
 
+func TestSystemNamed(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&ackActor{}, actor.WithName("acker"))
+	require.NoError(t, err)
+
+	_, err = sys.Spawn(&ackActor{}, actor.WithName("acker"))
+	require.Error(t, err)
+
+	found, ok := sys.Lookup("acker")
+	require.True(t, ok)
+	require.Equal(t, ref.String(), found.String())
+
+	reply, err := sys.AskName("acker", ackMsg{i: 7})
+	require.NoError(t, err)
+	require.Equal(t, 7, reply.(ackMsg).i)
+	require.NoError(t, sys.TellName("acker", ackMsg{i: 8}))
+
+	require.NoError(t, sys.Kill(ref, true))
+	_, ok = sys.Lookup("acker")
+	require.False(t, ok)
+	require.Error(t, sys.TellName("acker", ackMsg{}))
+
+	_, err = sys.Spawn(&ackActor{}, actor.WithName("acker"))
+	require.NoError(t, err)
+}
+
+func TestSystemTellType(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	actors := []*ackActor{}
+	for i := 0; i < 3; i++ {
+		a := &ackActor{ack: make(chan ackMsg, 1)}
+		_, err := sys.Spawn(a)
+		require.NoError(t, err)
+		actors = append(actors, a)
+	}
+	other := &countActor{}
+	_, err := sys.Spawn(other)
+	require.NoError(t, err)
+
+	require.NoError(t, actor.TellType[*ackActor](sys, ackMsg{i: 1}))
+	for _, a := range actors {
+		select {
+		case ack := <-a.ack:
+			require.Equal(t, 1, ack.i)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	require.NoError(t, actor.TellType[*simpleActor](sys, ackMsg{}))
+}
+
+type sleepMsg struct {
+	actor.Message
+	d time.Duration
+}
+
+type deadlineReply struct {
+	actor.Message
+	deadline time.Time
+	ok       bool
+}
+
+// sleepyActor sleeps before replying with the deadline of its context
+type sleepyActor struct{}
+
+func (sa *sleepyActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case sleepMsg:
+		time.Sleep(msg.d)
+		deadline, ok := ctx.Deadline()
+		return deadlineReply{deadline: deadline, ok: ok}, nil
+	}
+	return nil, nil
+}
+
+func TestSystemAskTimeout(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&sleepyActor{})
+	require.NoError(t, err)
+
+	_, err = sys.Ask(ref, sleepMsg{d: 200 * time.Millisecond}, actor.WithTimeout(10*time.Millisecond))
+	require.ErrorIs(t, err, actor.ErrTalkTimeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = sys.AskContext(ctx, ref, sleepMsg{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the deadline travels with the envelope
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	expected, _ := ctx.Deadline()
+	reply, err := sys.AskContext(ctx, ref, sleepMsg{})
+	require.NoError(t, err)
+	require.True(t, reply.(deadlineReply).ok)
+	require.Equal(t, expected, reply.(deadlineReply).deadline)
+
+	// no timeout, no deadline
+	reply, err = sys.Ask(ref, sleepMsg{}, actor.WithTimeout(0))
+	require.NoError(t, err)
+	require.False(t, reply.(deadlineReply).ok)
+}
+
+// capturingActor hands out the context of the message with i 0
+type capturingActor struct {
+	captured chan actor.Context
+}
+
+func (ca *capturingActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case ackMsg:
+		if msg.i == 0 {
+			ca.captured <- ctx
+		}
+		return msg, nil
+	}
+	return nil, nil
+}
+
+func TestSystemContextCaptured(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	a := &capturingActor{captured: make(chan actor.Context, 1)}
+	ref, err := sys.Spawn(a)
+	require.NoError(t, err)
+	_, err = sys.Ask(ref, ackMsg{i: 0}, actor.WithTimeout(time.Minute))
+	require.NoError(t, err)
+	ctx := <-a.captured
+	expected, ok := ctx.Deadline()
+	require.True(t, ok)
+
+	// the context of a handled message is not changed by the next ones
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		for i := 0; i < 100; i++ {
+			_ = ctx.Sender()
+			_, _ = ctx.Deadline()
+			_ = ctx.Envelope()
+		}
+	}()
+	for i := 1; i <= 100; i++ {
+		_, err = sys.Ask(ref, ackMsg{i: i}, actor.WithTimeout(time.Duration(i)*time.Second))
+		require.NoError(t, err)
+	}
+	<-read
+	deadline, _ := ctx.Deadline()
+	require.Equal(t, expected, deadline)
+	require.Equal(t, ackMsg{i: 0}, ctx.Envelope().Msg())
+	require.NotNil(t, ctx.Sender())
+}
+
+type correlatedMsg struct {
+	actor.Message
+	correlated bool
+	plain      bool
+}
+
+type idReply struct {
+	actor.Message
+	id uint64
+}
+
+// correlatingActor replies early, correlated or not, before
+// returning its actual reply
+type correlatingActor struct{}
+
+func (ca *correlatingActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case correlatedMsg:
+		id := ctx.Envelope().ID()
+		correlationID := id + 1
+		if msg.correlated {
+			correlationID = id
+		}
+		if msg.plain {
+			_ = ctx.Tell(ctx.Sender(), idReply{id: 0})
+		} else {
+			_ = ctx.Tell(ctx.Sender(), idReply{id: 0}, actor.WithCorrelationID(correlationID))
+		}
+		return idReply{id: id}, nil
+	}
+	return nil, nil
+}
+
+func TestSystemAskCorrelation(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	ref, err := sys.Spawn(&correlatingActor{})
+	require.NoError(t, err)
+
+	// misrouted early reply is rejected, the real one accepted
+	reply, err := sys.Ask(ref, correlatedMsg{correlated: false})
+	require.NoError(t, err)
+	require.NotZero(t, reply.(idReply).id)
+
+	// uncorrelated early reply is rejected as well
+	reply, err = sys.Ask(ref, correlatedMsg{plain: true})
+	require.NoError(t, err)
+	require.NotZero(t, reply.(idReply).id)
+
+	// correlated early reply wins, the real one arrives too late
+	reply, err = sys.Ask(ref, correlatedMsg{correlated: true})
+	require.NoError(t, err)
+	require.Zero(t, reply.(idReply).id)
+}
+
+// lifecycleActor reports Start and Stop and counts the other messages
+type lifecycleActor struct {
+	events chan string
+	cnt    int
+}
+
+func (la *lifecycleActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg.(type) {
+	case *actor.Start:
+		la.events <- "start"
+	case *actor.Stop:
+		la.events <- "stop"
+	default:
+		la.cnt++
+		return ackMsg{i: la.cnt}, nil
+	}
+	return nil, nil
+}
+
+func expectEvent(t *testing.T, events chan string, expected string) {
+	t.Helper()
+	select {
+	case event := <-events:
+		require.Equal(t, expected, event)
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for %s", expected)
+	}
+}
+
+func TestSystemPassivation(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	events := make(chan string, 10)
+	factory := func() actor.Actor { return &lifecycleActor{events: events} }
+	// well above the scheduling jitter, the asks must not race the passivation
+	ref, err := sys.Spawn(factory(), actor.WithFactory(factory), actor.WithPassivation(200*time.Millisecond))
+	require.NoError(t, err)
+	expectEvent(t, events, "start")
+
+	for i := 1; i <= 2; i++ {
+		reply, err := sys.Ask(ref, ackMsg{})
+		require.NoError(t, err)
+		require.Equal(t, i, reply.(ackMsg).i)
+	}
+	expectEvent(t, events, "stop")
+
+	// reactivated with a fresh instance on the next message
+	reply, err := sys.Ask(ref, ackMsg{})
+	require.NoError(t, err)
+	require.Equal(t, 1, reply.(ackMsg).i)
+	expectEvent(t, events, "start")
+	expectEvent(t, events, "stop")
+
+	require.NoError(t, sys.Kill(ref, true))
+	require.Error(t, sys.Tell(ref, ackMsg{}))
+}
+
+// selfTellingActor tells itself from its first Stop handler
+type selfTellingActor struct {
+	events chan string
+	told   *atomic.Bool
+}
+
+func (sa *selfTellingActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg.(type) {
+	case *actor.Start:
+		sa.events <- "start"
+	case *actor.Stop:
+		sa.events <- "stop"
+		if sa.told.CompareAndSwap(false, true) {
+			_ = ctx.Tell(ctx.Self(), ackMsg{})
+		}
+	case ackMsg:
+		sa.events <- "msg"
+	}
+	return nil, nil
+}
+
+func TestSystemPassivationSelfTell(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	events := make(chan string, 10)
+	told := &atomic.Bool{}
+	factory := func() actor.Actor { return &selfTellingActor{events: events, told: told} }
+	ref, err := sys.Spawn(factory(), actor.WithFactory(factory), actor.WithPassivation(20*time.Millisecond))
+	require.NoError(t, err)
+
+	// the message told while passivating reactivates the actor
+	for _, event := range []string{"start", "stop", "start", "msg", "stop"} {
+		expectEvent(t, events, event)
+	}
+	require.NoError(t, sys.Kill(ref, true))
+}
+
 type countActor struct {
 	cnt uint64
 }
@@ -119,7 +428,7 @@ var _ actor.Actor = (*countActor)(nil)
 
 func (ca *countActor) Handle(ctx actor.Context, msg actor.Message) (reply actor.Message, err error) {
 	switch msg.(type) {
-	case *actor.Stop:
+	case *actor.Start, *actor.Stop:
 	// noop
 	default:
 		ca.cnt++
@@ -133,7 +442,7 @@ func Benchmark_System_Ack(b *testing.B) {
 	sys.SetLogger(log.NewStdLogger().WithLevel(log.INFO))
 	defer sys.Stop()
 	act := &ackActor{ack: make(chan ackMsg, b.N)}
-	ref := sys.Spawn(act)
+	ref, _ := sys.Spawn(act)
 	b.StartTimer()
 	wg := sync.WaitGroup{}
 	wg.Add(1)
@@ -157,7 +466,7 @@ func Benchmark_System_Counter(b *testing.B) {
 	sys.SetLogger(log.NewStdLogger().WithLevel(log.INFO))
 	defer sys.Stop()
 	act := &countActor{}
-	ref := sys.Spawn(act)
+	ref, _ := sys.Spawn(act)
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
 		_, err := sys.Ask(ref, nil)
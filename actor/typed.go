@@ -0,0 +1,83 @@
+package actor
+
+import (
+	"context"
+	"reflect"
+)
+
+// TypedRef refers to an actor handling Req messages and replying with Resp
+type TypedRef[Req, Resp Message] struct {
+	ref Ref
+}
+
+// NewTypedRef wraps ref, it is up to the caller that the actor handles Req
+func NewTypedRef[Req, Resp Message](ref Ref) TypedRef[Req, Resp] {
+	return TypedRef[Req, Resp]{ref: ref}
+}
+
+// Ref returns the untyped ref
+func (r TypedRef[Req, Resp]) Ref() Ref {
+	return r.ref
+}
+
+func (r TypedRef[Req, Resp]) String() string {
+	return r.ref.String()
+}
+
+// Tell msg via a System or Context
+func (r TypedRef[Req, Resp]) Tell(via talker, msg Req, opts ...TalkOption) error {
+	return via.Tell(r.ref, msg, opts...)
+}
+
+// Ask msg via a System or Context
+func (r TypedRef[Req, Resp]) Ask(via talker, msg Req, opts ...TalkOption) (Resp, error) {
+	return AskT[Resp](via, r.ref, msg, opts...)
+}
+
+// AskContext asks msg via a System or Context until ctx is done
+func (r TypedRef[Req, Resp]) AskContext(ctx context.Context, via talker, msg Req, opts ...TalkOption) (Resp, error) {
+	return typedReply[Resp](via.AskContext(ctx, r.ref, msg, opts...))
+}
+
+// AskT asks any ref and expects a Resp, an *Error reply is returned as error
+func AskT[Resp Message](via talker, whom Ref, msg Message, opts ...TalkOption) (Resp, error) {
+	return typedReply[Resp](via.Ask(whom, msg, opts...))
+}
+
+func typedReply[Resp Message](reply Message, err error) (resp Resp, _ error) {
+	if err != nil {
+		return resp, err
+	}
+	if e, ok := reply.(*Error); ok {
+		return resp, e.Error
+	}
+	resp, ok := reply.(Resp)
+	if !ok && reply != nil {
+		return resp, ErrUnexpectedReply(reply, reflect.TypeFor[Resp]())
+	}
+	return resp, nil
+}
+
+// SpawnTyped spawns an actor handling Req messages with handler via a System or Context,
+// other messages than Req are answered with an error
+func SpawnTyped[Req, Resp Message](via supervisor, handler func(ctx Context, msg Req) (Resp, error), opts ...SpawnOption) (TypedRef[Req, Resp], error) {
+	ref, err := via.Spawn(&typedActor[Req, Resp]{handler: handler}, opts...)
+	if err != nil {
+		return TypedRef[Req, Resp]{}, err
+	}
+	return NewTypedRef[Req, Resp](ref), nil
+}
+
+type typedActor[Req, Resp Message] struct {
+	handler func(ctx Context, msg Req) (Resp, error)
+}
+
+func (ta *typedActor[Req, Resp]) Handle(ctx Context, msg Message) (Message, error) {
+	switch msg := msg.(type) {
+	case Req:
+		return ta.handler(ctx, msg)
+	case systemMessage:
+		return nil, nil
+	}
+	return nil, ErrUnexpectedMessage(msg, reflect.TypeFor[Req]())
+}
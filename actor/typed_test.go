@@ -0,0 +1,82 @@
+package actor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+type addMsg struct {
+	actor.Message
+	amount int
+}
+
+type sumMsg struct {
+	actor.Message
+	sum int
+}
+
+func spawnAdder(t *testing.T, sys actor.System) actor.TypedRef[addMsg, sumMsg] {
+	t.Helper()
+	sum := 0
+	ref, err := actor.SpawnTyped(sys, func(ctx actor.Context, msg addMsg) (sumMsg, error) {
+		if msg.amount < 0 {
+			return sumMsg{}, errors.New("negative")
+		}
+		sum += msg.amount
+		return sumMsg{sum: sum}, nil
+	})
+	require.NoError(t, err)
+	return ref
+}
+
+func TestTypedAsk(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	adder := spawnAdder(t, sys)
+
+	require.NoError(t, adder.Tell(sys, addMsg{amount: 1}))
+	sum, err := adder.Ask(sys, addMsg{amount: 2})
+	require.NoError(t, err)
+	require.Equal(t, 3, sum.sum)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	sum, err = adder.AskContext(ctx, sys, addMsg{amount: 3})
+	require.NoError(t, err)
+	require.Equal(t, 6, sum.sum)
+
+	// errors are returned as such
+	_, err = adder.Ask(sys, addMsg{amount: -1})
+	require.EqualError(t, err, "negative")
+}
+
+func TestTypedInterop(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	adder := spawnAdder(t, sys)
+
+	// the untyped ref still works
+	reply, err := sys.Ask(adder.Ref(), addMsg{amount: 1})
+	require.NoError(t, err)
+	require.Equal(t, sumMsg{sum: 1}, reply)
+	sum, err := actor.AskT[sumMsg](sys, adder.Ref(), addMsg{amount: 1})
+	require.NoError(t, err)
+	require.Equal(t, 2, sum.sum)
+
+	// unexpected messages and replies fail
+	_, err = actor.AskT[sumMsg](sys, adder.Ref(), ackMsg{})
+	require.Error(t, err)
+	_, err = actor.AskT[ackMsg](sys, adder.Ref(), addMsg{amount: 1})
+	require.Error(t, err)
+
+	// untyped refs can be typed
+	ref, err := sys.Spawn(&ackActor{})
+	require.NoError(t, err)
+	typed := actor.NewTypedRef[ackMsg, ackMsg](ref)
+	require.Equal(t, ref.String(), typed.String())
+}
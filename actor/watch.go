@@ -0,0 +1,117 @@
+package actor
+
+// Terminated is sent to the watchers of an actor once it stopped,
+// Reason is nil if it was stopped gracefully
+type Terminated struct {
+	Message
+	Ref    Ref
+	Reason error
+}
+
+func (*Terminated) systemMessage() {}
+
+// watch registers watcher to get Terminated when watched stops,
+// right away if it already is
+func (s *system) watch(watcher Ref, watched Ref) error {
+	wref, ok := watcher.(*localRef)
+	if !ok {
+		return ErrUnsupportedRef(watcher)
+	}
+	lref, ok := watched.(*localRef)
+	if !ok {
+		return ErrUnsupportedRef(watched)
+	}
+	s.lock.Lock()
+	w, ok := s.live(*wref)
+	if !ok {
+		s.lock.Unlock()
+		return ErrActorNotFound(watcher)
+	}
+	a, ok := s.live(*lref)
+	if !ok || a.terminated.Load() {
+		s.lock.Unlock()
+		return s.send(watcher, &Terminated{Ref: watched, Reason: ErrActorNotFound(watched)})
+	}
+	if a.watchers == nil {
+		a.watchers = map[*actor]struct{}{}
+	}
+	a.watchers[w] = struct{}{}
+	if w.watching == nil {
+		w.watching = map[*actor]struct{}{}
+	}
+	w.watching[a] = struct{}{}
+	s.lock.Unlock()
+	return nil
+}
+
+// live returns the actor of ref until it terminated, even if it was
+// unregistered to stop already, lock must be held
+func (s *system) live(ref localRef) (*actor, bool) {
+	if a, ok := s.actors[ref]; ok {
+		return a, true
+	}
+	a, ok := s.stopping[ref]
+	return a, ok
+}
+
+// unwatch removes the watch of watcher on watched
+func (s *system) unwatch(watcher Ref, watched Ref) error {
+	wref, ok := watcher.(*localRef)
+	if !ok {
+		return ErrUnsupportedRef(watcher)
+	}
+	lref, ok := watched.(*localRef)
+	if !ok {
+		return ErrUnsupportedRef(watched)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	w, wok := s.live(*wref)
+	a, aok := s.live(*lref)
+	if wok && aok {
+		delete(a.watchers, w)
+		delete(w.watching, a)
+	}
+	return nil
+}
+
+// terminate is called once the actor is stopped for good, it unregisters the
+// actor, stops its children and notifies its watchers
+func (a *actor) terminate(reason error) {
+	if !a.terminated.CompareAndSwap(false, true) {
+		return
+	}
+	a.log.Debug("> terminated: %v", reason)
+	defer close(a.done)
+	a.scheduler.cancelAll()
+	s := a.system
+	s.lock.Lock()
+	s.statsOf(a.typ).add(a.stats)
+	var stopped []*actor
+	if registered, ok := s.actors[a.ref]; ok && registered == a {
+		stopped = s.unregister(a)
+	}
+	delete(s.stopping, a.ref)
+	watchers := make([]*actor, 0, len(a.watchers))
+	for w := range a.watchers {
+		watchers = append(watchers, w)
+		delete(w.watching, a)
+	}
+	a.watchers = nil
+	for w := range a.watching {
+		delete(w.watchers, a)
+	}
+	a.watching = nil
+	s.lock.Unlock()
+	// children die with their parent
+	for _, child := range stopped {
+		if child != a {
+			child.stop(true)
+		}
+	}
+	for _, w := range watchers {
+		_ = s.send(&w.ref, &Terminated{Ref: &a.ref, Reason: reason})
+	}
+	s.events.Unsubscribe(&a.ref, nil)
+	s.events.Publish(&ActorStopped{Ref: &a.ref, Reason: reason})
+}
@@ -0,0 +1,139 @@
+package actor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thlcodes/go-actress/actor"
+)
+
+type watchMsg struct {
+	actor.Message
+	ref     actor.Ref
+	unwatch bool
+}
+
+// watchingActor watches refs and forwards Terminated
+type watchingActor struct {
+	terminated chan *actor.Terminated
+}
+
+func (wa *watchingActor) Handle(ctx actor.Context, msg actor.Message) (actor.Message, error) {
+	switch msg := msg.(type) {
+	case watchMsg:
+		if msg.unwatch {
+			return nil, ctx.Unwatch(msg.ref)
+		}
+		return nil, ctx.Watch(msg.ref)
+	case *actor.Terminated:
+		wa.terminated <- msg
+	}
+	return nil, nil
+}
+
+func expectTerminated(t *testing.T, terminated chan *actor.Terminated, ref actor.Ref) *actor.Terminated {
+	t.Helper()
+	select {
+	case msg := <-terminated:
+		require.Equal(t, ref.String(), msg.Ref.String())
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no terminated")
+	}
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	w := &watchingActor{terminated: make(chan *actor.Terminated, 10)}
+	watcher, err := sys.Spawn(w)
+	require.NoError(t, err)
+
+	// graceful kill
+	ref, err := sys.Spawn(&ackActor{})
+	require.NoError(t, err)
+	_, err = sys.Ask(watcher, watchMsg{ref: ref})
+	require.NoError(t, err)
+	require.NoError(t, sys.Kill(ref, true))
+	require.NoError(t, expectTerminated(t, w.terminated, ref).Reason)
+
+	// watching a dead actor
+	_, err = sys.Ask(watcher, watchMsg{ref: ref})
+	require.NoError(t, err)
+	require.Error(t, expectTerminated(t, w.terminated, ref).Reason)
+
+	// crashed child that must not be restarted
+	parent, err := sys.Spawn(&supervisorActor{starts: make(chan int, 10)}, actor.WithSupervisor(actor.OneForOne, 0, time.Minute))
+	require.NoError(t, err)
+	reply, err := sys.Ask(parent, spawnChild{id: 1})
+	require.NoError(t, err)
+	ref = reply.(childRef).Ref
+	_, err = sys.Ask(watcher, watchMsg{ref: ref})
+	require.NoError(t, err)
+	require.NoError(t, sys.Tell(ref, failMsg{}))
+	require.EqualError(t, expectTerminated(t, w.terminated, ref).Reason, "boom")
+
+	// passivated actor killed ungraceful
+	ref, err = sys.Spawn(&ackActor{}, actor.WithPassivation(time.Millisecond))
+	require.NoError(t, err)
+	_, err = sys.Ask(watcher, watchMsg{ref: ref})
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, sys.Kill(ref, false))
+	require.ErrorIs(t, expectTerminated(t, w.terminated, ref).Reason, actor.ErrActorKilled)
+
+	// unwatched
+	ref, err = sys.Spawn(&ackActor{})
+	require.NoError(t, err)
+	_, err = sys.Ask(watcher, watchMsg{ref: ref})
+	require.NoError(t, err)
+	_, err = sys.Ask(watcher, watchMsg{ref: ref, unwatch: true})
+	require.NoError(t, err)
+	require.NoError(t, sys.Kill(ref, false))
+	select {
+	case msg := <-w.terminated:
+		t.Fatalf("unexpected terminated of %s", msg.Ref)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestWatchStopping(t *testing.T) {
+	sys := newSystem()
+	defer sys.Stop()
+	w := &watchingActor{terminated: make(chan *actor.Terminated, 10)}
+	watcher, err := sys.Spawn(w)
+	require.NoError(t, err)
+	other := &watchingActor{terminated: make(chan *actor.Terminated, 10)}
+	otherWatcher, err := sys.Spawn(other)
+	require.NoError(t, err)
+
+	// the busy actor keeps draining its mailbox after a graceful kill
+	ref, err := sys.Spawn(&orderActor{order: make(chan int, 10)})
+	require.NoError(t, err)
+	_, err = sys.Ask(otherWatcher, watchMsg{ref: ref})
+	require.NoError(t, err)
+	gate, entered := make(chan struct{}), make(chan struct{})
+	require.NoError(t, sys.Tell(ref, gateMsg{gate: gate, entered: entered}))
+	<-entered
+	require.NoError(t, sys.Kill(ref, true))
+
+	// watching and unwatching still work until it stopped
+	_, err = sys.Ask(watcher, watchMsg{ref: ref})
+	require.NoError(t, err)
+	_, err = sys.Ask(otherWatcher, watchMsg{ref: ref, unwatch: true})
+	require.NoError(t, err)
+	select {
+	case msg := <-w.terminated:
+		t.Fatalf("terminated while stopping: %v", msg.Reason)
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(gate)
+	require.NoError(t, expectTerminated(t, w.terminated, ref).Reason)
+	select {
+	case <-other.terminated:
+		t.Fatal("unwatched actor reported")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -13,6 +13,12 @@ type countingActor struct {
 	control actor.Ref
 }
 
+// counterCmd is handled by the countingActor
+type counterCmd interface {
+	actor.Message
+	counterCmd()
+}
+
 type counterAdd struct {
 	actor.Message
 	amount int
@@ -27,16 +33,17 @@ type counterSub struct {
 	amount int
 }
 
+func (counterAdd) counterCmd() {}
+func (counterHi) counterCmd()  {}
+func (counterSub) counterCmd() {}
+
 type counterState struct {
 	actor.Message
 	count int
 }
 
-func (a *countingActor) Handle(ctx actor.Context, msg actor.Message) (reply actor.Message, err error) {
+func (a *countingActor) Handle(ctx actor.Context, msg counterCmd) (reply counterState, err error) {
 	log.Printf("msg %T %s", msg, ctx.Sender())
-	if msg == nil {
-		print("nil")
-	}
 	check := false
 	switch msg := msg.(type) {
 	case counterHi:
@@ -86,40 +93,49 @@ func (ca *controlActor) Handle(ctx actor.Context, msg actor.Message) (reply acto
 
 func main() {
 	sys := actor.NewSystem(context.TODO())
-	defer sys.Stop()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := sys.Shutdown(ctx); err != nil {
+			log.Printf("shutdown: %s", err)
+		}
+	}()
 
 	control := &controlActor{close: make(chan struct{})}
-	controlRef := sys.Spawn(control)
+	controlRef, err := sys.Spawn(control)
+	if err != nil {
+		panic(err)
+	}
 	counter := &countingActor{control: controlRef}
-	counterRef := sys.Spawn(counter)
+	counterRef, err := actor.SpawnTyped(sys, counter.Handle)
+	if err != nil {
+		panic(err)
+	}
 
 	// non-waiting tell
-	if err := sys.Tell(counterRef, counterHi{}); err != nil {
+	if err := counterRef.Tell(sys, counterHi{}); err != nil {
 		panic(err)
 	}
 
-	time.Sleep(1 * time.Millisecond)
 	// ask  waiting for a response
-	if state, err := sys.Ask(counterRef, counterAdd{amount: 150}); err != nil {
+	if state, err := counterRef.Ask(sys, counterAdd{amount: 150}); err != nil {
 		panic(err)
 	} else {
-		log.Printf("count %d should be 150 now", state.(counterState).count)
+		log.Printf("count %d should be 150 now", state.count)
 	}
 
-	time.Sleep(1 * time.Millisecond)
 	// ask  waiting for a response
-	if state, err := sys.Ask(counterRef, counterSub{amount: 70}); err != nil {
+	if state, err := counterRef.Ask(sys, counterSub{amount: 70}); err != nil {
 		panic(err)
 	} else {
-		log.Printf("count %d should be 80 now", state.(counterState).count)
+		log.Printf("count %d should be 80 now", state.count)
 	}
 
-	time.Sleep(1 * time.Millisecond)
 	// remove enough to trigger control quit
-	if state, err := sys.Ask(counterRef, counterSub{amount: 81}); err != nil {
+	if state, err := counterRef.Ask(sys, counterSub{amount: 81}); err != nil {
 		panic(err)
 	} else {
-		log.Printf("count %d should be -1 now, this control should quit", state.(counterState).count)
+		log.Printf("count %d should be -1 now, this control should quit", state.count)
 	}
 
 	<-control.close
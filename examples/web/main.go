@@ -12,6 +12,7 @@ import (
 	"slices"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/thlcodes/go-actress/actor"
 	logger "github.com/thlcodes/go-actress/log"
@@ -51,6 +52,8 @@ type usersStatus struct {
 	UserCount     int `json:"user_count"`
 }
 
+const usersActorName = "users"
+
 type UsersActor struct {
 	users []User
 }
@@ -87,18 +90,29 @@ func (u *UsersActor) Handle(ctx actor.Context, msg actor.Message) (reply actor.M
 }
 
 func main() {
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
 	log.Printf("starting with PID %d", os.Getpid())
-	sys := actor.NewSystem(ctx)
+	sys := actor.NewSystem(context.Background())
 	sys.SetLogger(logger.NewStdLogger().WithLevel(logger.INFO))
 
-	usersActor := sys.Spawn(&UsersActor{users: []User{}})
+	if _, err := sys.Spawn(&UsersActor{users: []User{}}, actor.WithName(usersActorName)); err != nil {
+		log.Fatalf("could not spawn users actor: %s", err)
+	}
+
+	// ask the users actor, bound to the lifetime of the request
+	askUsers := func(r *http.Request, msg actor.Message) (actor.Message, error) {
+		usersActor, ok := sys.Lookup(usersActorName)
+		if !ok {
+			return nil, fmt.Errorf("users actor is gone")
+		}
+		return sys.AskContext(r.Context(), usersActor, msg, actor.WithTimeout(actor.AskTimeout))
+	}
 
 	http.HandleFunc("GET /users", func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("GET /users")
-		reply, err := sys.Ask(usersActor, getUsers{})
+		reply, err := askUsers(r, getUsers{})
 		if err != nil {
 			w.WriteHeader(500)
 			fmt.Fprintf(w, "could get add user: %s", err.Error())
@@ -128,7 +142,7 @@ func main() {
 			return
 		}
 
-		reply, err := sys.Ask(usersActor, addUser{Name: user.Name})
+		reply, err := askUsers(r, addUser{Name: user.Name})
 		if err != nil {
 			w.WriteHeader(500)
 			fmt.Fprintf(w, "could not add user: %s", err.Error())
@@ -154,7 +168,7 @@ func main() {
 			return
 		}
 
-		reply, err := sys.Ask(usersActor, deleteUser{Id: userId})
+		reply, err := askUsers(r, deleteUser{Id: userId})
 		if err != nil {
 			w.WriteHeader(500)
 			fmt.Fprintf(w, "could not delete user: %s", err.Error())
@@ -171,8 +185,16 @@ func main() {
 		}
 	})
 
+	http.Handle("GET /metrics", actor.MetricsHandler(sys.Metrics()))
+
 	go func() { _ = http.ListenAndServe("localhost:8080", nil) }()
 
 	<-ctx.Done()
+	// let the actors finish the requests in flight
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sys.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown: %s", err)
+	}
 	os.Exit(0)
 }